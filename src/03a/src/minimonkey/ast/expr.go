@@ -2,7 +2,9 @@ package ast
 
 import (
 	"bytes"
+	"math/big"
 	"minimonkey/token"
+	"strconv"
 	"strings"
 )
 
@@ -18,17 +20,55 @@ type Identifier struct {
 
 func (i *Identifier) expressionNode()      {}
 func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
+func (i *Identifier) Pos() token.Pos       { return i.Token.Pos }
+func (i *Identifier) End() token.Pos       { return i.Token.End }
 func (i *Identifier) String() string       { return i.Value }
 
 type IntegerLiteral struct {
 	Token token.Token
 	Value int64
+	Big   *big.Int // int64に収まらない場合のみ設定する
 }
 
 func (il *IntegerLiteral) expressionNode()      {}
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
+func (il *IntegerLiteral) Pos() token.Pos       { return il.Token.Pos }
+func (il *IntegerLiteral) End() token.Pos       { return il.Token.End }
 func (il *IntegerLiteral) String() string       { return il.Token.Literal }
 
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode()      {}
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FloatLiteral) Pos() token.Pos       { return fl.Token.Pos }
+func (fl *FloatLiteral) End() token.Pos       { return fl.Token.End }
+func (fl *FloatLiteral) String() string       { return fl.Token.Literal }
+
+type StringLiteral struct {
+	Token token.Token
+	Value string
+}
+
+func (sl *StringLiteral) expressionNode()      {}
+func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StringLiteral) Pos() token.Pos       { return sl.Token.Pos }
+func (sl *StringLiteral) End() token.Pos       { return sl.Token.End }
+func (sl *StringLiteral) String() string       { return strconv.Quote(sl.Value) }
+
+type Boolean struct {
+	Token token.Token
+	Value bool
+}
+
+func (b *Boolean) expressionNode()      {}
+func (b *Boolean) TokenLiteral() string { return b.Token.Literal }
+func (b *Boolean) Pos() token.Pos       { return b.Token.Pos }
+func (b *Boolean) End() token.Pos       { return b.Token.End }
+func (b *Boolean) String() string       { return b.Token.Literal }
+
 type PrefixExpression struct {
 	Token    token.Token
 	Operator string
@@ -37,6 +77,8 @@ type PrefixExpression struct {
 
 func (pexp *PrefixExpression) expressionNode()      {}
 func (pexp *PrefixExpression) TokenLiteral() string { return pexp.Token.Literal }
+func (pexp *PrefixExpression) Pos() token.Pos       { return pexp.Token.Pos }
+func (pexp *PrefixExpression) End() token.Pos       { return pexp.Right.End() }
 func (pexp *PrefixExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
@@ -55,6 +97,8 @@ type InfixExpression struct {
 
 func (iexp *InfixExpression) expressionNode()      {}
 func (iexp *InfixExpression) TokenLiteral() string { return iexp.Token.Literal }
+func (iexp *InfixExpression) Pos() token.Pos       { return iexp.Left.Pos() }
+func (iexp *InfixExpression) End() token.Pos       { return iexp.Right.End() }
 func (iexp *InfixExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
@@ -65,39 +109,117 @@ func (iexp *InfixExpression) String() string {
 	return out.String()
 }
 
+type IfExpression struct {
+	Token       token.Token // token.IF
+	Condition   Expression
+	Consequence *BlockStatement
+	Alternative *BlockStatement // elseがない場合はnil
+}
+
+func (ie *IfExpression) expressionNode()      {}
+func (ie *IfExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IfExpression) Pos() token.Pos       { return ie.Token.Pos }
+func (ie *IfExpression) End() token.Pos {
+	if ie.Alternative != nil {
+		return ie.Alternative.End()
+	}
+	return ie.Consequence.End()
+}
+func (ie *IfExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("if ")
+	out.WriteString(ie.Condition.String())
+	out.WriteString(" ")
+	out.WriteString(ie.Consequence.String())
+
+	if ie.Alternative != nil {
+		out.WriteString(" else ")
+		out.WriteString(ie.Alternative.String())
+	}
+
+	return out.String()
+}
+
 type FunctionLiteral struct {
 	Token      token.Token // token.FUNCTION
 	Parameters []*Identifier
+	Defaults   []Expression // Parametersと同じ長さで、デフォルト値のない引数はnil
+	Rest       *Identifier  // 残りの引数を配列として受け取る引数（...rest）
 	Body       *BlockStatement
+	Name       string // letで束縛された場合の名前（エラーメッセージ用）
 }
 
 func (fl *FunctionLiteral) expressionNode()      {}
 func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FunctionLiteral) Pos() token.Pos       { return fl.Token.Pos }
+func (fl *FunctionLiteral) End() token.Pos       { return fl.Body.End() }
 func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
 
-	params := make([]string, len(fl.Parameters))
-	for i, p := range fl.Parameters {
-		params[i] = p.String()
-	}
-
 	out.WriteString(fl.TokenLiteral())
 	out.WriteString("(")
-	out.WriteString(strings.Join(params, ","))
+	out.WriteString(strings.Join(fl.parameterStrings(), ","))
 	out.WriteString(")")
 	out.WriteString(fl.Body.String())
 
 	return out.String()
 }
 
+func (fl *FunctionLiteral) parameterStrings() []string {
+	params := []string{}
+	for i, p := range fl.Parameters {
+		if d := fl.Default(i); d != nil {
+			params = append(params, p.String()+" = "+d.String())
+		} else {
+			params = append(params, p.String())
+		}
+	}
+	if fl.Rest != nil {
+		params = append(params, "..."+fl.Rest.String())
+	}
+	return params
+}
+
+// 名前と引数の並び（例: add(a, b = 1, ...rest)）。無名の場合はfnを名前とする。
+func (fl *FunctionLiteral) Signature() string {
+	name := fl.Name
+	if name == "" {
+		name = fl.TokenLiteral()
+	}
+	return name + "(" + strings.Join(fl.parameterStrings(), ", ") + ")"
+}
+
+// デフォルト値のない引数の数
+func (fl *FunctionLiteral) NumRequired() int {
+	n := 0
+	for i := range fl.Parameters {
+		if fl.Default(i) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// i番目の引数のデフォルト値（ない場合はnil）
+func (fl *FunctionLiteral) Default(i int) Expression {
+	if i >= len(fl.Defaults) {
+		return nil
+	}
+	return fl.Defaults[i]
+}
+
 type CallExpression struct {
 	Token     token.Token // "("
 	Function  Expression  // Identifier or FunctionLiteral
 	Arguments []Expression
+	Rparen    token.Pos // ")"の位置
 }
 
 func (ce *CallExpression) expressionNode()      {}
 func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CallExpression) Pos() token.Pos       { return ce.Function.Pos() }
+func (ce *CallExpression) End() token.Pos       { return after(ce.Rparen) }
 func (ce *CallExpression) String() string {
 	var out bytes.Buffer
 
@@ -113,3 +235,105 @@ func (ce *CallExpression) String() string {
 
 	return out.String()
 }
+
+// 呼び出し時に展開される引数（f(...xs)）
+type SpreadExpression struct {
+	Token token.Token // token.ELLIPSIS
+	Value Expression
+}
+
+func (se *SpreadExpression) expressionNode()      {}
+func (se *SpreadExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SpreadExpression) Pos() token.Pos       { return se.Token.Pos }
+func (se *SpreadExpression) End() token.Pos       { return se.Value.End() }
+func (se *SpreadExpression) String() string       { return "..." + se.Value.String() }
+
+// 呼び出し時のキーワード引数（f(b: 3)）
+type KeywordArgument struct {
+	Name  *Identifier
+	Value Expression
+}
+
+func (ka *KeywordArgument) expressionNode()      {}
+func (ka *KeywordArgument) TokenLiteral() string { return ka.Name.TokenLiteral() }
+func (ka *KeywordArgument) Pos() token.Pos       { return ka.Name.Pos() }
+func (ka *KeywordArgument) End() token.Pos       { return ka.Value.End() }
+func (ka *KeywordArgument) String() string       { return ka.Name.String() + ": " + ka.Value.String() }
+
+type ArrayLiteral struct {
+	Token    token.Token // token.LBRACKET
+	Elements []Expression
+	Rbrack   token.Pos // "]"の位置
+}
+
+func (al *ArrayLiteral) expressionNode()      {}
+func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
+func (al *ArrayLiteral) Pos() token.Pos       { return al.Token.Pos }
+func (al *ArrayLiteral) End() token.Pos       { return after(al.Rbrack) }
+func (al *ArrayLiteral) String() string {
+	var out bytes.Buffer
+
+	elements := make([]string, len(al.Elements))
+	for i, e := range al.Elements {
+		elements[i] = e.String()
+	}
+
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ","))
+	out.WriteString("]")
+
+	return out.String()
+}
+
+type HashPair struct {
+	Key   Expression
+	Value Expression
+}
+
+type HashLiteral struct {
+	Token  token.Token // token.LBRACE
+	Pairs  []HashPair  // 記述順を保持する
+	Rbrace token.Pos   // "}"の位置
+}
+
+func (hl *HashLiteral) expressionNode()      {}
+func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
+func (hl *HashLiteral) Pos() token.Pos       { return hl.Token.Pos }
+func (hl *HashLiteral) End() token.Pos       { return after(hl.Rbrace) }
+func (hl *HashLiteral) String() string {
+	var out bytes.Buffer
+
+	pairs := make([]string, len(hl.Pairs))
+	for i, pair := range hl.Pairs {
+		pairs[i] = pair.Key.String() + ":" + pair.Value.String()
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ","))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+type IndexExpression struct {
+	Token  token.Token // token.LBRACKET
+	Left   Expression
+	Index  Expression
+	Rbrack token.Pos // "]"の位置
+}
+
+func (ie *IndexExpression) expressionNode()      {}
+func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) Pos() token.Pos       { return ie.Left.Pos() }
+func (ie *IndexExpression) End() token.Pos       { return after(ie.Rbrack) }
+func (ie *IndexExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ie.Left.String())
+	out.WriteString("[")
+	out.WriteString(ie.Index.String())
+	out.WriteString("])")
+
+	return out.String()
+}
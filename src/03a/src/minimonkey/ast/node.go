@@ -1,6 +1,20 @@
 package ast
 
+import "minimonkey/token"
+
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Pos() token.Pos // ノードの開始位置
+	End() token.Pos // ノードの直後の位置
+}
+
+// 1文字の区切り記号（")"など）の直後の位置
+func after(pos token.Pos) token.Pos {
+	if !pos.IsValid() {
+		return pos
+	}
+	pos.Offset += 1
+	pos.Column += 1
+	return pos
 }
@@ -16,16 +16,24 @@ type EmptyStatement struct {
 
 func (es *EmptyStatement) statementNode()       {}
 func (es *EmptyStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *EmptyStatement) Pos() token.Pos       { return es.Token.Pos }
+func (es *EmptyStatement) End() token.Pos       { return es.Token.End }
 func (es *EmptyStatement) String() string       { return "" }
 
+// let x = 1; または const x = 1;
 type LetStatement struct {
-	Token token.Token
+	Token token.Token // token.LETまたはtoken.CONST
 	Name  *Identifier
 	Value Expression
 }
 
+// 再代入できない束縛であればtrue
+func (ls *LetStatement) IsConst() bool { return ls.Token.Type == token.CONST }
+
 func (ls *LetStatement) statementNode()       {}
 func (ls *LetStatement) TokenLiteral() string { return ls.Token.Literal }
+func (ls *LetStatement) Pos() token.Pos       { return ls.Token.Pos }
+func (ls *LetStatement) End() token.Pos       { return ls.Value.End() }
 func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 
@@ -42,6 +50,29 @@ func (ls *LetStatement) String() string {
 	return out.String()
 }
 
+// 定義済みの変数への代入（x = 1, x += 1など）
+type AssignStatement struct {
+	Token    token.Token // 代入演算子
+	Name     *Identifier
+	Operator string // "=", "+=", "-=", "*=", "/="
+	Value    Expression
+}
+
+func (as *AssignStatement) statementNode()       {}
+func (as *AssignStatement) TokenLiteral() string { return as.Token.Literal }
+func (as *AssignStatement) Pos() token.Pos       { return as.Name.Pos() }
+func (as *AssignStatement) End() token.Pos       { return as.Value.End() }
+func (as *AssignStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(as.Name.String())
+	out.WriteString(" " + as.Operator + " ")
+	out.WriteString(as.Value.String())
+	out.WriteString(";")
+
+	return out.String()
+}
+
 type ExpressionStatement struct {
 	Token      token.Token
 	Expression Expression
@@ -49,6 +80,8 @@ type ExpressionStatement struct {
 
 func (es *ExpressionStatement) statementNode()       {}
 func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExpressionStatement) Pos() token.Pos       { return es.Token.Pos }
+func (es *ExpressionStatement) End() token.Pos       { return es.Expression.End() }
 func (es *ExpressionStatement) String() string {
 	var out bytes.Buffer
 
@@ -67,6 +100,13 @@ type ReturnStatement struct {
 
 func (rs *ReturnStatement) statementNode()       {}
 func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *ReturnStatement) Pos() token.Pos       { return rs.Token.Pos }
+func (rs *ReturnStatement) End() token.Pos {
+	if rs.ReturnValue != nil {
+		return rs.ReturnValue.End()
+	}
+	return rs.Token.End
+}
 func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
 
@@ -84,10 +124,13 @@ func (rs *ReturnStatement) String() string {
 type BlockStatement struct {
 	Token      token.Token // token.LBRACE
 	Statements []Statement
+	Rbrace     token.Pos // "}"の位置
 }
 
 func (bs *BlockStatement) statementNode()       {}
 func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BlockStatement) Pos() token.Pos       { return bs.Token.Pos }
+func (bs *BlockStatement) End() token.Pos       { return after(bs.Rbrace) }
 func (bs *BlockStatement) String() string {
 	var out bytes.Buffer
 
@@ -104,3 +147,72 @@ func (bs *BlockStatement) String() string {
 
 	return out.String()
 }
+
+// while (<condition>) { <statement>... }
+type WhileStatement struct {
+	Token     token.Token // token.WHILE
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (ws *WhileStatement) statementNode()       {}
+func (ws *WhileStatement) TokenLiteral() string { return ws.Token.Literal }
+func (ws *WhileStatement) Pos() token.Pos       { return ws.Token.Pos }
+func (ws *WhileStatement) End() token.Pos       { return ws.Body.End() }
+func (ws *WhileStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("while ")
+	out.WriteString(ws.Condition.String())
+	out.WriteString(" ")
+	out.WriteString(ws.Body.String())
+	out.WriteString(";")
+
+	return out.String()
+}
+
+// for (<identifier> in <expression>) { <statement>... }
+type ForStatement struct {
+	Token    token.Token // token.FOR
+	Variable *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fs *ForStatement) statementNode()       {}
+func (fs *ForStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *ForStatement) Pos() token.Pos       { return fs.Token.Pos }
+func (fs *ForStatement) End() token.Pos       { return fs.Body.End() }
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for (")
+	out.WriteString(fs.Variable.String())
+	out.WriteString(" in ")
+	out.WriteString(fs.Iterable.String())
+	out.WriteString(") ")
+	out.WriteString(fs.Body.String())
+	out.WriteString(";")
+
+	return out.String()
+}
+
+type BreakStatement struct {
+	Token token.Token
+}
+
+func (bs *BreakStatement) statementNode()       {}
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BreakStatement) Pos() token.Pos       { return bs.Token.Pos }
+func (bs *BreakStatement) End() token.Pos       { return bs.Token.End }
+func (bs *BreakStatement) String() string       { return "break;" }
+
+type ContinueStatement struct {
+	Token token.Token
+}
+
+func (cs *ContinueStatement) statementNode()       {}
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ContinueStatement) Pos() token.Pos       { return cs.Token.Pos }
+func (cs *ContinueStatement) End() token.Pos       { return cs.Token.End }
+func (cs *ContinueStatement) String() string       { return "continue;" }
@@ -0,0 +1,281 @@
+// 仮想マシンが実行するバイトコードの命令
+//
+// 命令は1バイトのオペコードと、それに続くビッグエンディアンのオペランドからなる。
+package code
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"minimonkey/token"
+)
+
+type Instructions []byte
+
+type Opcode byte
+
+const (
+	OpConstant Opcode = iota // 定数をスタックに積む
+	OpPop
+
+	OpAdd
+	OpSub
+	OpMul
+	OpDiv
+	OpMod
+
+	OpBitAnd
+	OpBitOr
+	OpBitXor
+	OpShiftLeft
+	OpShiftRight
+	OpRange          // 終端を含む範囲
+	OpRangeExclusive // 終端を含まない範囲
+
+	OpEqual
+	OpNotEqual
+	OpLessThan
+	OpGreaterThan
+	OpLessEqual
+	OpGreaterEqual
+
+	OpMinus
+	OpBang
+	OpBitNot
+
+	OpTrue
+	OpFalse
+	OpNull
+
+	OpJump
+	OpJumpNotTruthy // スタックから取り出した値が偽であればジャンプする
+
+	OpGetGlobal
+	OpSetGlobal
+	OpGetLocal
+	OpSetLocal
+	OpGetFree
+	OpCurrentClosure // 実行中のクロージャを積む（再帰呼び出し用）
+	OpAssignGlobal   // 定義済みのグローバル変数に代入する
+
+	OpMakeCell // ローカル変数をCellに格納する（格納済みであれば何もしない）
+	OpNewCell  // ローカル変数に新しい空のCellを格納する（ループの繰り返しごとの変数）
+	OpGetLocalCell
+	OpSetLocalCell
+	OpGetFreeCell
+	OpSetFreeCell
+
+	OpArray
+	OpHash
+	OpIndex
+
+	OpIter     // スタックから取り出した値の反復の状態を積む
+	OpIterNext // 反復の次の要素を積む（要素がなければジャンプする）
+
+	OpCall
+	OpReturnValue
+	OpClosure
+
+	OpSpread      // スタックから取り出した値を展開される引数として積む
+	OpKeyword     // スタックから取り出した値をキーワード引数として積む
+	OpJumpIfBound // 引数が渡されていればデフォルト値の評価を飛ばす
+)
+
+type Definition struct {
+	Name          string
+	OperandWidths []int // 各オペランドのバイト数
+}
+
+var definitions = map[Opcode]*Definition{
+	OpConstant: {"OpConstant", []int{2}},
+	OpPop:      {"OpPop", []int{}},
+
+	OpAdd: {"OpAdd", []int{}},
+	OpSub: {"OpSub", []int{}},
+	OpMul: {"OpMul", []int{}},
+	OpDiv: {"OpDiv", []int{}},
+	OpMod: {"OpMod", []int{}},
+
+	OpBitAnd:     {"OpBitAnd", []int{}},
+	OpBitOr:      {"OpBitOr", []int{}},
+	OpBitXor:     {"OpBitXor", []int{}},
+	OpShiftLeft:  {"OpShiftLeft", []int{}},
+	OpShiftRight: {"OpShiftRight", []int{}},
+
+	OpRange:          {"OpRange", []int{}},
+	OpRangeExclusive: {"OpRangeExclusive", []int{}},
+
+	OpEqual:        {"OpEqual", []int{}},
+	OpNotEqual:     {"OpNotEqual", []int{}},
+	OpLessThan:     {"OpLessThan", []int{}},
+	OpGreaterThan:  {"OpGreaterThan", []int{}},
+	OpLessEqual:    {"OpLessEqual", []int{}},
+	OpGreaterEqual: {"OpGreaterEqual", []int{}},
+
+	OpMinus:  {"OpMinus", []int{}},
+	OpBang:   {"OpBang", []int{}},
+	OpBitNot: {"OpBitNot", []int{}},
+
+	OpTrue:  {"OpTrue", []int{}},
+	OpFalse: {"OpFalse", []int{}},
+	OpNull:  {"OpNull", []int{}},
+
+	OpJump:          {"OpJump", []int{2}},
+	OpJumpNotTruthy: {"OpJumpNotTruthy", []int{2}},
+
+	OpGetGlobal:      {"OpGetGlobal", []int{2}},
+	OpSetGlobal:      {"OpSetGlobal", []int{2}},
+	OpGetLocal:       {"OpGetLocal", []int{1}},
+	OpSetLocal:       {"OpSetLocal", []int{1}},
+	OpGetFree:        {"OpGetFree", []int{1}},
+	OpCurrentClosure: {"OpCurrentClosure", []int{}},
+	OpAssignGlobal:   {"OpAssignGlobal", []int{2}},
+
+	OpMakeCell:     {"OpMakeCell", []int{1}},
+	OpNewCell:      {"OpNewCell", []int{1}},
+	OpGetLocalCell: {"OpGetLocalCell", []int{1}},
+	OpSetLocalCell: {"OpSetLocalCell", []int{1}},
+	OpGetFreeCell:  {"OpGetFreeCell", []int{1}},
+	OpSetFreeCell:  {"OpSetFreeCell", []int{1}},
+
+	OpArray: {"OpArray", []int{2}}, // 要素数
+	OpHash:  {"OpHash", []int{2}},  // キーと値の合計数
+	OpIndex: {"OpIndex", []int{}},
+
+	OpIter:     {"OpIter", []int{}},
+	OpIterNext: {"OpIterNext", []int{2}}, // 反復の終了時のジャンプ先
+
+	OpCall:        {"OpCall", []int{1}}, // 引数の数
+	OpReturnValue: {"OpReturnValue", []int{}},
+	OpClosure:     {"OpClosure", []int{2, 1}}, // 関数の定数の位置、自由変数の数
+
+	OpSpread:      {"OpSpread", []int{}},
+	OpKeyword:     {"OpKeyword", []int{2}},        // 引数の名前の定数の位置
+	OpJumpIfBound: {"OpJumpIfBound", []int{1, 2}}, // 引数のローカル変数の位置、ジャンプ先
+}
+
+func Lookup(op byte) (*Definition, error) {
+	def, ok := definitions[Opcode(op)]
+	if !ok {
+		return nil, fmt.Errorf("opcode %d undefined", op)
+	}
+	return def, nil
+}
+
+// 命令をエンコードする
+func Make(op Opcode, operands ...int) []byte {
+	def, ok := definitions[op]
+	if !ok {
+		return []byte{}
+	}
+
+	length := 1
+	for _, w := range def.OperandWidths {
+		length += w
+	}
+
+	ins := make([]byte, length)
+	ins[0] = byte(op)
+
+	offset := 1
+	for i, o := range operands {
+		w := def.OperandWidths[i]
+		switch w {
+		case 2:
+			binary.BigEndian.PutUint16(ins[offset:], uint16(o))
+		case 1:
+			ins[offset] = byte(o)
+		}
+		offset += w
+	}
+
+	return ins
+}
+
+// オペランドをデコードし、読み込んだバイト数とともに返す
+func ReadOperands(def *Definition, ins Instructions) ([]int, int) {
+	operands := make([]int, len(def.OperandWidths))
+	offset := 0
+
+	for i, w := range def.OperandWidths {
+		switch w {
+		case 2:
+			operands[i] = int(ReadUint16(ins[offset:]))
+		case 1:
+			operands[i] = int(ReadUint8(ins[offset:]))
+		}
+		offset += w
+	}
+
+	return operands, offset
+}
+
+func ReadUint16(ins Instructions) uint16 {
+	return binary.BigEndian.Uint16(ins)
+}
+
+func ReadUint8(ins Instructions) uint8 {
+	return uint8(ins[0])
+}
+
+// 逆アセンブルした命令を1行に1つずつ表示する
+//
+//	0000 OpConstant 0
+//	0003 OpConstant 1
+//	0006 OpAdd
+func (ins Instructions) String() string {
+	var out bytes.Buffer
+
+	i := 0
+	for i < len(ins) {
+		def, err := Lookup(ins[i])
+		if err != nil {
+			fmt.Fprintf(&out, "ERROR: %s\n", err)
+			return out.String()
+		}
+
+		operands, read := ReadOperands(def, ins[i+1:])
+
+		fmt.Fprintf(&out, "%04d %s\n", i, ins.fmtInstruction(def, operands))
+
+		i += 1 + read
+	}
+
+	return out.String()
+}
+
+func (ins Instructions) fmtInstruction(def *Definition, operands []int) string {
+	if len(operands) != len(def.OperandWidths) {
+		return fmt.Sprintf("ERROR: operand len %d does not match defined %d\n", len(operands), len(def.OperandWidths))
+	}
+
+	switch len(operands) {
+	case 0:
+		return def.Name
+	case 1:
+		return fmt.Sprintf("%s %d", def.Name, operands[0])
+	case 2:
+		return fmt.Sprintf("%s %d %d", def.Name, operands[0], operands[1])
+	}
+
+	return fmt.Sprintf("ERROR: unhandled operand count for %s\n", def.Name)
+}
+
+// 命令の位置とソースコード上の位置の対応（Offsetの昇順に並べる）
+type PosTable []PosEntry
+
+type PosEntry struct {
+	Offset int // 命令の先頭のバイトオフセット
+	Pos    token.Pos
+}
+
+// offsetの命令を生成したソースコード上の位置を返す
+func (t PosTable) Lookup(offset int) token.Pos {
+	i := sort.Search(len(t), func(i int) bool { return t[i].Offset > offset })
+	if i == 0 {
+		return token.Pos{}
+	}
+	return t[i-1].Pos
+}
@@ -0,0 +1,104 @@
+package code
+
+import (
+	"minimonkey/token"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		op       Opcode
+		operands []int
+		expected []byte
+	}{
+		{OpConstant, []int{65534}, []byte{byte(OpConstant), 255, 254}},
+		{OpAdd, []int{}, []byte{byte(OpAdd)}},
+		{OpGetLocal, []int{255}, []byte{byte(OpGetLocal), 255}},
+		{OpClosure, []int{65534, 255}, []byte{byte(OpClosure), 255, 254, 255}},
+	}
+
+	for _, tt := range tests {
+		ins := Make(tt.op, tt.operands...)
+
+		if string(ins) != string(tt.expected) {
+			t.Errorf("Make(%d, %v) got %v, expected %v", tt.op, tt.operands, ins, tt.expected)
+		}
+	}
+}
+
+func TestReadOperands(t *testing.T) {
+	tests := []struct {
+		op        Opcode
+		operands  []int
+		bytesRead int
+	}{
+		{OpConstant, []int{65535}, 2},
+		{OpGetLocal, []int{255}, 1},
+		{OpClosure, []int{65535, 255}, 3},
+	}
+
+	for _, tt := range tests {
+		ins := Make(tt.op, tt.operands...)
+
+		def, err := Lookup(byte(tt.op))
+		if err != nil {
+			t.Fatalf("definition not found: %s", err)
+		}
+
+		operands, n := ReadOperands(def, ins[1:])
+		if n != tt.bytesRead {
+			t.Errorf("n got %d, expected %d", n, tt.bytesRead)
+		}
+
+		for i, want := range tt.operands {
+			if operands[i] != want {
+				t.Errorf("operands[%d] got %d, expected %d", i, operands[i], want)
+			}
+		}
+	}
+}
+
+func TestInstructionsString(t *testing.T) {
+	var ins Instructions
+	ins = append(ins, Make(OpAdd)...)
+	ins = append(ins, Make(OpGetLocal, 1)...)
+	ins = append(ins, Make(OpConstant, 2)...)
+	ins = append(ins, Make(OpConstant, 65535)...)
+	ins = append(ins, Make(OpClosure, 65535, 255)...)
+
+	expected := `0000 OpAdd
+0001 OpGetLocal 1
+0003 OpConstant 2
+0006 OpConstant 65535
+0009 OpClosure 65535 255
+`
+
+	if ins.String() != expected {
+		t.Errorf("ins.String() got %q, expected %q", ins.String(), expected)
+	}
+}
+
+func TestPosTableLookup(t *testing.T) {
+	table := PosTable{
+		{Offset: 0, Pos: token.Pos{Line: 1, Column: 1}},
+		{Offset: 3, Pos: token.Pos{Line: 1, Column: 5}},
+		{Offset: 7, Pos: token.Pos{Line: 2, Column: 1}},
+	}
+
+	tests := []struct {
+		offset   int
+		expected string
+	}{
+		{0, "1:1"},
+		{2, "1:1"},
+		{3, "1:5"},
+		{6, "1:5"},
+		{10, "2:1"},
+	}
+
+	for _, tt := range tests {
+		if got := table.Lookup(tt.offset).String(); got != tt.expected {
+			t.Errorf("Lookup(%d) got %s, expected %s", tt.offset, got, tt.expected)
+		}
+	}
+}
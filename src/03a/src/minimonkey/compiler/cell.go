@@ -0,0 +1,74 @@
+package compiler
+
+import "minimonkey/ast"
+
+// 関数の本体（入れ子の関数を含む）で代入される名前を集める。
+//
+// クロージャは自由変数の値をコピーして捕捉するため、代入される可能性のあるローカル変数は
+// object.Cellに格納し、関数とクロージャの間でCellを共有する。
+// 名前のみで判定するため、同名の別の変数も対象となるが動作には影響しない。
+func assignedNames(node ast.Node, names map[string]bool) {
+	switch node := node.(type) {
+	case *ast.BlockStatement:
+		for _, s := range node.Statements {
+			assignedNames(s, names)
+		}
+	case *ast.AssignStatement:
+		names[node.Name.Value] = true
+		assignedNames(node.Value, names)
+	case *ast.LetStatement:
+		assignedNames(node.Value, names)
+	case *ast.ExpressionStatement:
+		assignedNames(node.Expression, names)
+	case *ast.ReturnStatement:
+		if node.ReturnValue != nil {
+			assignedNames(node.ReturnValue, names)
+		}
+	case *ast.WhileStatement:
+		assignedNames(node.Condition, names)
+		assignedNames(node.Body, names)
+	case *ast.ForStatement:
+		assignedNames(node.Iterable, names)
+		assignedNames(node.Body, names)
+
+	case *ast.PrefixExpression:
+		assignedNames(node.Right, names)
+	case *ast.InfixExpression:
+		assignedNames(node.Left, names)
+		assignedNames(node.Right, names)
+	case *ast.IfExpression:
+		assignedNames(node.Condition, names)
+		assignedNames(node.Consequence, names)
+		if node.Alternative != nil {
+			assignedNames(node.Alternative, names)
+		}
+	case *ast.FunctionLiteral:
+		for _, d := range node.Defaults {
+			if d != nil {
+				assignedNames(d, names)
+			}
+		}
+		assignedNames(node.Body, names)
+	case *ast.CallExpression:
+		assignedNames(node.Function, names)
+		for _, arg := range node.Arguments {
+			assignedNames(arg, names)
+		}
+	case *ast.SpreadExpression:
+		assignedNames(node.Value, names)
+	case *ast.KeywordArgument:
+		assignedNames(node.Value, names)
+	case *ast.ArrayLiteral:
+		for _, el := range node.Elements {
+			assignedNames(el, names)
+		}
+	case *ast.HashLiteral:
+		for _, pair := range node.Pairs {
+			assignedNames(pair.Key, names)
+			assignedNames(pair.Value, names)
+		}
+	case *ast.IndexExpression:
+		assignedNames(node.Left, names)
+		assignedNames(node.Index, names)
+	}
+}
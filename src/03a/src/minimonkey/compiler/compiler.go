@@ -0,0 +1,827 @@
+// 構文木を仮想マシンが実行するバイトコードに変換する
+package compiler
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"minimonkey/ast"
+	"minimonkey/code"
+	"minimonkey/evalutor"
+	"minimonkey/object"
+	"minimonkey/token"
+)
+
+type Compiler struct {
+	constants []object.Object
+
+	symbolTable *SymbolTable
+
+	scopes     []CompilationScope
+	scopeIndex int
+
+	pos token.Pos // コンパイル中のノードの位置（生成した命令に対応付ける）
+}
+
+// 関数ごとに生成中の命令を保持する
+type CompilationScope struct {
+	instructions code.Instructions
+	positions    code.PosTable
+	loops        []*loop // コンパイル中のループ（内側のものが末尾）
+}
+
+// breakとcontinueのジャンプ先
+type loop struct {
+	start  int   // continueのジャンプ先
+	breaks []int // ループの終了位置が決まった後に書き換えるジャンプ命令の位置
+}
+
+type Bytecode struct {
+	Instructions code.Instructions
+	Positions    code.PosTable
+	Constants    []object.Object
+	Globals      []string // エラーメッセージ用のグローバル変数の名前
+	Locals       []string // メインのフレームのローカル変数（最も外側のループの本体の変数）の名前
+}
+
+func New() *Compiler {
+	return NewWithState(NewSymbolTable(), []object.Object{})
+}
+
+// 以前のコンパイルで定義したグローバル変数と定数を引き継ぐ
+// （メインのフレームのローカル変数は実行ごとに作られるため引き継がない）
+func NewWithState(s *SymbolTable, constants []object.Object) *Compiler {
+	s.blockNames = nil
+
+	return &Compiler{
+		constants:   constants,
+		symbolTable: s,
+		scopes:      []CompilationScope{{}},
+	}
+}
+
+func (c *Compiler) Compile(node ast.Node) error {
+	pos := c.pos
+	c.pos = node.Pos()
+	defer func() { c.pos = pos }()
+
+	switch node := node.(type) {
+
+	// Statements
+	case *ast.Program:
+		// 最後の文の値をプログラムの結果とする
+		if err := c.compileStatements(node.Statements); err != nil {
+			return err
+		}
+		c.emit(code.OpReturnValue)
+
+	case ast.Statement:
+		return c.compileStatement(node, true)
+
+	// Expressions
+	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			return c.emitConstant(&object.BigInt{Value: node.Big})
+		}
+		return c.emitConstant(&object.Integer{Value: node.Value})
+
+	case *ast.FloatLiteral:
+		return c.emitConstant(&object.Float{Value: node.Value})
+
+	case *ast.StringLiteral:
+		return c.emitConstant(&object.String{Value: node.Value})
+
+	case *ast.Boolean:
+		if node.Value {
+			c.emit(code.OpTrue)
+		} else {
+			c.emit(code.OpFalse)
+		}
+
+	case *ast.PrefixExpression:
+		if err := c.Compile(node.Right); err != nil {
+			return err
+		}
+
+		switch node.Operator {
+		case "-":
+			c.emit(code.OpMinus)
+		case "!":
+			c.emit(code.OpBang)
+		case "~":
+			c.emit(code.OpBitNot)
+		default:
+			return c.errorf("unknown operator %s", node.Operator)
+		}
+
+	case *ast.InfixExpression:
+		op, ok := infixOperators[node.Operator]
+		if !ok {
+			return c.errorf("unknown operator %s", node.Operator)
+		}
+
+		if err := c.Compile(node.Left); err != nil {
+			return err
+		}
+		if err := c.Compile(node.Right); err != nil {
+			return err
+		}
+
+		c.emit(op)
+
+	case *ast.Identifier:
+		return c.compileIdentifier(node)
+
+	case *ast.IfExpression:
+		return c.compileIfExpression(node)
+
+	case *ast.FunctionLiteral:
+		return c.compileFunctionLiteral(node, "")
+
+	case *ast.CallExpression:
+		if err := c.Compile(node.Function); err != nil {
+			return err
+		}
+
+		for _, arg := range node.Arguments {
+			if err := c.Compile(arg); err != nil {
+				return err
+			}
+		}
+
+		if len(node.Arguments) > math.MaxUint8 {
+			return c.errorf("too many arguments: %d", len(node.Arguments))
+		}
+		c.emit(code.OpCall, len(node.Arguments))
+
+	case *ast.SpreadExpression:
+		if err := c.Compile(node.Value); err != nil {
+			return err
+		}
+		c.emit(code.OpSpread)
+
+	case *ast.KeywordArgument:
+		if err := c.Compile(node.Value); err != nil {
+			return err
+		}
+		idx, err := c.addConstant(&object.String{Value: node.Name.Value})
+		if err != nil {
+			return err
+		}
+		c.emit(code.OpKeyword, idx)
+
+	case *ast.ArrayLiteral:
+		for _, el := range node.Elements {
+			if err := c.Compile(el); err != nil {
+				return err
+			}
+		}
+
+		if len(node.Elements) > math.MaxUint16 {
+			return c.errorf("too many array elements: %d", len(node.Elements))
+		}
+		c.emit(code.OpArray, len(node.Elements))
+
+	case *ast.HashLiteral:
+		for _, pair := range node.Pairs {
+			if err := c.Compile(pair.Key); err != nil {
+				return err
+			}
+			if err := c.Compile(pair.Value); err != nil {
+				return err
+			}
+		}
+
+		if len(node.Pairs)*2 > math.MaxUint16 {
+			return c.errorf("too many hash pairs: %d", len(node.Pairs))
+		}
+		c.emit(code.OpHash, len(node.Pairs)*2)
+
+	case *ast.IndexExpression:
+		if err := c.Compile(node.Left); err != nil {
+			return err
+		}
+		if err := c.Compile(node.Index); err != nil {
+			return err
+		}
+
+		c.emit(code.OpIndex)
+
+	default:
+		return c.errorf("unsupported node %T", node)
+	}
+
+	return nil
+}
+
+var infixOperators = map[string]code.Opcode{
+	"..":  code.OpRange,
+	"..<": code.OpRangeExclusive,
+	"+":   code.OpAdd,
+	"-":   code.OpSub,
+	"*":   code.OpMul,
+	"/":   code.OpDiv,
+	"%":   code.OpMod,
+	"&":   code.OpBitAnd,
+	"|":   code.OpBitOr,
+	"^":   code.OpBitXor,
+	"<<":  code.OpShiftLeft,
+	">>":  code.OpShiftRight,
+	"==":  code.OpEqual,
+	"!=":  code.OpNotEqual,
+	"<":   code.OpLessThan,
+	">":   code.OpGreaterThan,
+	"<=":  code.OpLessEqual,
+	">=":  code.OpGreaterEqual,
+}
+
+func (c *Compiler) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("%s: %s", c.pos, fmt.Sprintf(format, a...))
+}
+
+// 文の並びをコンパイルし、最後の文の値をスタックに残す（文がない場合はNULL）
+func (c *Compiler) compileStatements(stmts []ast.Statement) error {
+	if len(stmts) == 0 {
+		c.emit(code.OpNull)
+		return nil
+	}
+
+	for i, s := range stmts {
+		pos := c.pos
+		c.pos = s.Pos()
+		err := c.compileStatement(s, i == len(stmts)-1)
+		c.pos = pos
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// valueが真であれば文の値をスタックに残す
+func (c *Compiler) compileStatement(s ast.Statement, value bool) error {
+	switch s := s.(type) {
+	case *ast.ExpressionStatement:
+		if err := c.Compile(s.Expression); err != nil {
+			return err
+		}
+		if !value {
+			c.emit(code.OpPop)
+		}
+
+	case *ast.LetStatement:
+		return c.compileLetStatement(s, value)
+
+	case *ast.AssignStatement:
+		return c.compileAssignStatement(s, value)
+
+	case *ast.WhileStatement:
+		return c.compileWhileStatement(s, value)
+
+	case *ast.ForStatement:
+		return c.compileForStatement(s, value)
+
+	case *ast.BreakStatement:
+		l := c.currentLoop()
+		if l == nil {
+			return c.errorf("break is not in a loop")
+		}
+		l.breaks = append(l.breaks, c.emit(code.OpJump, 9999))
+
+	case *ast.ContinueStatement:
+		l := c.currentLoop()
+		if l == nil {
+			return c.errorf("continue is not in a loop")
+		}
+		c.emit(code.OpJump, l.start)
+
+	case *ast.EmptyStatement:
+		if value {
+			c.emit(code.OpNull)
+		}
+
+	case *ast.ReturnStatement:
+		if s.ReturnValue == nil {
+			c.emit(code.OpNull)
+		} else if err := c.Compile(s.ReturnValue); err != nil {
+			return err
+		}
+		c.emit(code.OpReturnValue)
+
+	case *ast.BlockStatement:
+		if err := c.compileStatements(s.Statements); err != nil {
+			return err
+		}
+		if !value {
+			c.emit(code.OpPop)
+		}
+
+	default:
+		return c.errorf("unsupported statement %T", s)
+	}
+
+	return nil
+}
+
+func (c *Compiler) compileLetStatement(s *ast.LetStatement, value bool) error {
+	if err := c.checkDeclaration(s); err != nil {
+		return err
+	}
+
+	fl, isFunction := s.Value.(*ast.FunctionLiteral)
+
+	cellReady := false
+
+	switch {
+	case c.symbolTable.Outer == nil && !c.symbolTable.InBlock():
+		// グローバル変数は先に定義し、値の中での代入をconstの検査の対象とする
+		if _, err := c.defineSymbol(s.Name.Value, s.IsConst()); err != nil {
+			return err
+		}
+		if err := c.Compile(s.Value); err != nil {
+			return err
+		}
+	case isFunction && c.symbolTable.cells[s.Name.Value]:
+		// 代入される関数は、先にCellを用意して自由変数として自身を参照させる
+		symbol, err := c.defineSymbol(s.Name.Value, s.IsConst())
+		if err != nil {
+			return err
+		}
+		c.makeCell(symbol)
+		cellReady = true
+
+		if err := c.compileFunctionLiteral(fl, ""); err != nil {
+			return err
+		}
+	case isFunction:
+		// ローカルな関数は自身の名前で再帰呼び出しできるようにする
+		if err := c.compileFunctionLiteral(fl, s.Name.Value); err != nil {
+			return err
+		}
+	default:
+		if err := c.Compile(s.Value); err != nil {
+			return err
+		}
+	}
+
+	symbol, err := c.defineSymbol(s.Name.Value, s.IsConst())
+	if err != nil {
+		return err
+	}
+
+	if cellReady {
+		c.emit(code.OpSetLocalCell, symbol.Index)
+	} else {
+		c.setSymbol(symbol)
+	}
+	if value {
+		c.loadSymbol(symbol)
+	}
+
+	return nil
+}
+
+// 同じスコープでの再宣言は、constであれば常に、letであればstrictの場合にエラーとする
+func (c *Compiler) checkDeclaration(s *ast.LetStatement) error {
+	name := s.Name.Value
+
+	if !c.symbolTable.Declared(name) {
+		return nil
+	}
+
+	if sym, ok := c.symbolTable.Resolve(name); ok && sym.Const {
+		return c.errorf("cannot redeclare constant: %s", name)
+	}
+	if c.symbolTable.Strict() {
+		return c.errorf("identifier already declared: %s", name)
+	}
+
+	return nil
+}
+
+func (c *Compiler) defineSymbol(name string, constant bool) (Symbol, error) {
+	var symbol Symbol
+	if constant {
+		symbol = c.symbolTable.DefineConst(name)
+	} else {
+		symbol = c.symbolTable.Define(name)
+	}
+
+	if symbol.Scope == LocalScope && symbol.Index > math.MaxUint8 {
+		return symbol, c.errorf("too many local variables")
+	}
+	return symbol, nil
+}
+
+// ループの本体はブロックのスコープとしてコンパイルする（値は残さない）
+func (c *Compiler) compileWhileStatement(s *ast.WhileStatement, value bool) error {
+	start := len(c.currentInstructions())
+
+	if err := c.Compile(s.Condition); err != nil {
+		return err
+	}
+	exitPos := c.emit(code.OpJumpNotTruthy, 9999)
+
+	if err := c.compileLoopBody(start, s.Body, nil); err != nil {
+		return err
+	}
+	c.emit(code.OpJump, start)
+
+	end := len(c.currentInstructions())
+	c.changeOperand(exitPos, end)
+	c.leaveLoop(end)
+
+	if value {
+		c.emit(code.OpNull)
+	}
+
+	return nil
+}
+
+// 反復の状態をスタックに置き、要素がなくなるかbreakするとループの後で取り除く
+func (c *Compiler) compileForStatement(s *ast.ForStatement, value bool) error {
+	if err := c.Compile(s.Iterable); err != nil {
+		return err
+	}
+
+	pos := c.pos
+	c.pos = s.Iterable.Pos()
+	c.emit(code.OpIter)
+	c.pos = pos
+
+	start := c.emit(code.OpIterNext, 9999)
+
+	if err := c.compileLoopBody(start, s.Body, s.Variable); err != nil {
+		return err
+	}
+	c.emit(code.OpJump, start)
+
+	end := len(c.currentInstructions())
+	c.changeOperand(start, end)
+	c.leaveLoop(end)
+
+	c.emit(code.OpPop)
+	if value {
+		c.emit(code.OpNull)
+	}
+
+	return nil
+}
+
+// variableがnilでなければ、スタックに積まれた要素をブロックの中の変数に代入してから本体を実行する
+func (c *Compiler) compileLoopBody(start int, body *ast.BlockStatement, variable *ast.Identifier) error {
+	scope := &c.scopes[c.scopeIndex]
+	scope.loops = append(scope.loops, &loop{start: start})
+
+	// 最も外側のループの本体の変数はメインのフレームのローカル変数となるため、
+	// 関数と同様に代入される名前をCellに格納する
+	if c.symbolTable.Outer == nil {
+		if c.symbolTable.cells == nil {
+			c.symbolTable.cells = make(map[string]bool)
+		}
+		assignedNames(body, c.symbolTable.cells)
+	}
+
+	c.symbolTable.EnterBlock()
+	defer c.symbolTable.LeaveBlock()
+
+	if variable != nil {
+		symbol, err := c.defineSymbol(variable.Value, false)
+		if err != nil {
+			return err
+		}
+		c.setSymbol(symbol)
+	}
+
+	return c.compileStatement(body, false)
+}
+
+// 最も内側のループを終了し、breakのジャンプ先をendとする
+func (c *Compiler) leaveLoop(end int) {
+	scope := &c.scopes[c.scopeIndex]
+	l := scope.loops[len(scope.loops)-1]
+	scope.loops = scope.loops[:len(scope.loops)-1]
+
+	for _, pos := range l.breaks {
+		c.changeOperand(pos, end)
+	}
+}
+
+func (c *Compiler) currentLoop() *loop {
+	loops := c.scopes[c.scopeIndex].loops
+	if len(loops) == 0 {
+		return nil
+	}
+	return loops[len(loops)-1]
+}
+
+// 定義されていない名前への代入は実行時にエラーとする（後から定義されるグローバル変数かもしれない）
+func (c *Compiler) compileAssignStatement(s *ast.AssignStatement, value bool) error {
+	symbol, ok := c.symbolTable.Resolve(s.Name.Value)
+	if !ok {
+		symbol = c.symbolTable.root().reserve(s.Name.Value)
+	}
+
+	if symbol.Const {
+		return c.errorf("cannot assign to constant: %s", s.Name.Value)
+	}
+
+	if s.Operator != "=" {
+		op := infixOperators[strings.TrimSuffix(s.Operator, "=")]
+
+		c.loadSymbol(symbol)
+		if err := c.Compile(s.Value); err != nil {
+			return err
+		}
+		c.emit(op)
+	} else if err := c.Compile(s.Value); err != nil {
+		return err
+	}
+
+	switch {
+	case symbol.Scope == GlobalScope:
+		c.emit(code.OpAssignGlobal, symbol.Index)
+	case symbol.Scope == LocalScope && symbol.Cell:
+		c.emit(code.OpSetLocalCell, symbol.Index)
+	case symbol.Scope == LocalScope:
+		c.emit(code.OpSetLocal, symbol.Index)
+	case symbol.Scope == FreeScope && symbol.Cell:
+		c.emit(code.OpSetFreeCell, symbol.Index)
+	default:
+		// 代入される名前はすべてCellとなるため、ここには到達しない
+		return c.errorf("cannot assign to %s", s.Name.Value)
+	}
+
+	if value {
+		c.loadSymbol(symbol)
+	}
+
+	return nil
+}
+
+// 定義されていない名前は組み込み関数を探し、それもなければ
+// 実行時に定義されているかもしれないグローバル変数として扱う
+func (c *Compiler) compileIdentifier(node *ast.Identifier) error {
+	symbol, ok := c.symbolTable.Resolve(node.Value)
+	if ok {
+		c.loadSymbol(symbol)
+		return nil
+	}
+
+	if builtin, ok := evalutor.LookupBuiltin(node.Value); ok {
+		return c.emitConstant(builtin)
+	}
+
+	c.loadSymbol(c.symbolTable.root().reserve(node.Value))
+	return nil
+}
+
+func (c *Compiler) compileIfExpression(node *ast.IfExpression) error {
+	if err := c.Compile(node.Condition); err != nil {
+		return err
+	}
+
+	jumpNotTruthyPos := c.emit(code.OpJumpNotTruthy, 9999)
+
+	if err := c.Compile(node.Consequence); err != nil {
+		return err
+	}
+
+	jumpPos := c.emit(code.OpJump, 9999)
+	c.changeOperand(jumpNotTruthyPos, len(c.currentInstructions()))
+
+	if node.Alternative == nil {
+		c.emit(code.OpNull)
+	} else if err := c.Compile(node.Alternative); err != nil {
+		return err
+	}
+
+	c.changeOperand(jumpPos, len(c.currentInstructions()))
+
+	return nil
+}
+
+// nameが空でなければ関数の中でその名前により自身を参照できる
+func (c *Compiler) compileFunctionLiteral(node *ast.FunctionLiteral, name string) error {
+	c.enterScope()
+
+	c.symbolTable.cells = make(map[string]bool)
+	assignedNames(node, c.symbolTable.cells)
+
+	if name != "" {
+		c.symbolTable.DefineFunctionName(name)
+	}
+
+	params := make([]Symbol, len(node.Parameters))
+	for i, p := range node.Parameters {
+		params[i] = c.symbolTable.Define(p.Value)
+	}
+	if node.Rest != nil {
+		params = append(params, c.symbolTable.Define(node.Rest.Value))
+	}
+
+	// 渡されなかった引数にデフォルト値を格納してからCellに格納する
+	for i, symbol := range params {
+		if d := node.Default(i); d != nil {
+			if err := c.compileDefault(symbol, d); err != nil {
+				c.leaveScope()
+				return err
+			}
+		}
+		if symbol.Cell {
+			c.emit(code.OpMakeCell, symbol.Index)
+		}
+	}
+
+	pos := c.pos
+	c.pos = node.Body.Pos()
+	err := c.compileStatements(node.Body.Statements)
+	c.emit(code.OpReturnValue)
+	c.pos = pos
+
+	if err != nil {
+		c.leaveScope()
+		return err
+	}
+
+	freeSymbols := c.symbolTable.FreeSymbols
+	localNames := c.symbolTable.names()
+	positions := c.scopes[c.scopeIndex].positions
+	instructions := c.leaveScope()
+
+	if len(localNames) > math.MaxUint8 {
+		return c.errorf("too many local variables")
+	}
+	if len(freeSymbols) > math.MaxUint8 {
+		return c.errorf("too many free variables")
+	}
+
+	freeNames := make([]string, len(freeSymbols))
+	for i, s := range freeSymbols {
+		freeNames[i] = s.Name
+		c.captureSymbol(s)
+	}
+
+	fn := &object.CompiledFunction{
+		Instructions:  instructions,
+		Positions:     positions,
+		NumLocals:     len(localNames),
+		NumParameters: len(params),
+		LocalNames:    localNames,
+		FreeNames:     freeNames,
+		Literal:       node,
+	}
+
+	idx, err := c.addConstant(fn)
+	if err != nil {
+		return err
+	}
+	c.emit(code.OpClosure, idx, len(freeSymbols))
+
+	return nil
+}
+
+func (c *Compiler) compileDefault(param Symbol, value ast.Expression) error {
+	jumpPos := c.emit(code.OpJumpIfBound, param.Index, 9999)
+
+	if err := c.Compile(value); err != nil {
+		return err
+	}
+	c.emit(code.OpSetLocal, param.Index)
+
+	ins := c.currentInstructions()
+	copy(ins[jumpPos:], code.Make(code.OpJumpIfBound, param.Index, len(ins)))
+
+	return nil
+}
+
+func (c *Compiler) loadSymbol(s Symbol) {
+	switch s.Scope {
+	case GlobalScope:
+		c.emit(code.OpGetGlobal, s.Index)
+	case LocalScope:
+		if s.Cell {
+			c.emit(code.OpGetLocalCell, s.Index)
+		} else {
+			c.emit(code.OpGetLocal, s.Index)
+		}
+	case FreeScope:
+		if s.Cell {
+			c.emit(code.OpGetFreeCell, s.Index)
+		} else {
+			c.emit(code.OpGetFree, s.Index)
+		}
+	case FunctionScope:
+		c.emit(code.OpCurrentClosure)
+	}
+}
+
+// クロージャが捕捉する値を積む（Cellは中身ではなくCellそのものを積む）
+func (c *Compiler) captureSymbol(s Symbol) {
+	switch {
+	case s.Scope == LocalScope && s.Cell:
+		c.emit(code.OpGetLocal, s.Index)
+	case s.Scope == FreeScope && s.Cell:
+		c.emit(code.OpGetFree, s.Index)
+	default:
+		c.loadSymbol(s)
+	}
+}
+
+// letによる定義
+func (c *Compiler) setSymbol(s Symbol) {
+	switch {
+	case s.Scope == GlobalScope:
+		c.emit(code.OpSetGlobal, s.Index)
+	case s.Cell:
+		c.makeCell(s)
+		c.emit(code.OpSetLocalCell, s.Index)
+	default:
+		c.emit(code.OpSetLocal, s.Index)
+	}
+}
+
+// ループの本体では繰り返しごとに別の変数となるように新しいCellを用意する
+func (c *Compiler) makeCell(s Symbol) {
+	if c.symbolTable.InBlock() {
+		c.emit(code.OpNewCell, s.Index)
+	} else {
+		c.emit(code.OpMakeCell, s.Index)
+	}
+}
+
+func (c *Compiler) addConstant(obj object.Object) (int, error) {
+	if len(c.constants) > math.MaxUint16 {
+		return 0, c.errorf("too many constants")
+	}
+	c.constants = append(c.constants, obj)
+	return len(c.constants) - 1, nil
+}
+
+func (c *Compiler) emitConstant(obj object.Object) error {
+	idx, err := c.addConstant(obj)
+	if err != nil {
+		return err
+	}
+	c.emit(code.OpConstant, idx)
+	return nil
+}
+
+// 命令を追加してその位置を返す
+func (c *Compiler) emit(op code.Opcode, operands ...int) int {
+	ins := code.Make(op, operands...)
+
+	scope := &c.scopes[c.scopeIndex]
+	pos := len(scope.instructions)
+	scope.instructions = append(scope.instructions, ins...)
+
+	if n := len(scope.positions); n == 0 || scope.positions[n-1].Pos != c.pos {
+		scope.positions = append(scope.positions, code.PosEntry{Offset: pos, Pos: c.pos})
+	}
+
+	return pos
+}
+
+func (c *Compiler) currentInstructions() code.Instructions {
+	return c.scopes[c.scopeIndex].instructions
+}
+
+func (c *Compiler) changeOperand(opPos int, operand int) {
+	ins := c.currentInstructions()
+	op := code.Opcode(ins[opPos])
+	copy(ins[opPos:], code.Make(op, operand))
+}
+
+func (c *Compiler) enterScope() {
+	c.scopes = append(c.scopes, CompilationScope{})
+	c.scopeIndex += 1
+	c.symbolTable = NewEnclosedSymbolTable(c.symbolTable)
+}
+
+func (c *Compiler) leaveScope() code.Instructions {
+	instructions := c.currentInstructions()
+
+	c.scopes = c.scopes[:len(c.scopes)-1]
+	c.scopeIndex -= 1
+	c.symbolTable = c.symbolTable.Outer
+
+	return instructions
+}
+
+// 以降のコンパイルに引き継ぐグローバル変数のシンボルテーブル
+func (c *Compiler) SymbolTable() *SymbolTable {
+	return c.symbolTable
+}
+
+func (c *Compiler) Bytecode() *Bytecode {
+	return &Bytecode{
+		Instructions: c.currentInstructions(),
+		Positions:    c.scopes[c.scopeIndex].positions,
+		Constants:    c.constants,
+		Globals:      c.symbolTable.root().names(),
+		Locals:       c.symbolTable.root().mainLocalNames(),
+	}
+}
@@ -0,0 +1,301 @@
+package compiler
+
+import (
+	"minimonkey/code"
+	"minimonkey/lexer"
+	"minimonkey/object"
+	"minimonkey/parser"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		input     string
+		constants []string
+		expected  []code.Instructions
+	}{
+		{
+			"1 + 2",
+			[]string{"1", "2"},
+			[]code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpAdd),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			"1; 2 < 3",
+			[]string{"1", "2", "3"},
+			[]code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpPop),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpLessThan),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			"let x = 1; x",
+			[]string{"1"},
+			[]code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			"if (true) { 10 }",
+			[]string{"10"},
+			[]code.Instructions{
+				code.Make(code.OpTrue),              // 0000
+				code.Make(code.OpJumpNotTruthy, 10), // 0001
+				code.Make(code.OpConstant, 0),       // 0004
+				code.Make(code.OpJump, 11),          // 0007
+				code.Make(code.OpNull),              // 0010
+				code.Make(code.OpReturnValue),       // 0011
+			},
+		},
+		{
+			`[1, "a"][0]`,
+			[]string{"1", "a", "0"},
+			[]code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpArray, 2),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpIndex),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			"fn(a) { fn(b) { a + b } }",
+			[]string{
+				"0000 OpGetFree 0\n0002 OpGetLocal 0\n0004 OpAdd\n0005 OpReturnValue\n",
+				"0000 OpGetLocal 0\n0002 OpClosure 0 1\n0006 OpReturnValue\n",
+			},
+			[]code.Instructions{
+				code.Make(code.OpClosure, 1, 0),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			"let x = 1; x += 2",
+			[]string{"1", "2"},
+			[]code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpAdd),
+				code.Make(code.OpAssignGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			// 代入される自由変数はCellを共有する
+			"fn(a) { fn() { a = 1 } }",
+			[]string{
+				"1",
+				"0000 OpConstant 0\n0003 OpSetFreeCell 0\n0005 OpGetFreeCell 0\n0007 OpReturnValue\n",
+				"0000 OpMakeCell 0\n0002 OpGetLocal 0\n0004 OpClosure 1 1\n0008 OpReturnValue\n",
+			},
+			[]code.Instructions{
+				code.Make(code.OpClosure, 2, 0),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			"while (true) { continue }",
+			[]string{},
+			[]code.Instructions{
+				code.Make(code.OpTrue),
+				code.Make(code.OpJumpNotTruthy, 11),
+				code.Make(code.OpJump, 0),
+				code.Make(code.OpPop),
+				code.Make(code.OpJump, 0),
+				code.Make(code.OpNull),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			// breakは反復の状態を取り除く位置にジャンプする
+			"for (x in [1]) { break }",
+			[]string{"1"},
+			[]code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpArray, 1),
+				code.Make(code.OpIter),
+				code.Make(code.OpIterNext, 19),
+				code.Make(code.OpSetLocal, 0),
+				code.Make(code.OpJump, 19),
+				code.Make(code.OpPop),
+				code.Make(code.OpJump, 7),
+				code.Make(code.OpPop),
+				code.Make(code.OpNull),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			// 渡されなかった引数にだけデフォルト値を格納する
+			"fn(a = 1) { a }",
+			[]string{
+				"1",
+				"0000 OpJumpIfBound 0 9\n0004 OpConstant 0\n0007 OpSetLocal 0\n0009 OpGetLocal 0\n0011 OpReturnValue\n",
+			},
+			[]code.Instructions{
+				code.Make(code.OpClosure, 1, 0),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			"f(...xs, b: 1)",
+			[]string{"1", "b"},
+			[]code.Instructions{
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpGetGlobal, 1),
+				code.Make(code.OpSpread),
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpKeyword, 1),
+				code.Make(code.OpCall, 2),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			"0..<3",
+			[]string{"0", "3"},
+			[]code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpRangeExclusive),
+				code.Make(code.OpReturnValue),
+			},
+		},
+		{
+			"len",
+			[]string{"builtin function len"},
+			[]code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpReturnValue),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).Parse()
+
+		c := New()
+		if err := c.Compile(program); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		bytecode := c.Bytecode()
+
+		var expected code.Instructions
+		for _, ins := range tt.expected {
+			expected = append(expected, ins...)
+		}
+		if bytecode.Instructions.String() != expected.String() {
+			t.Errorf("%q: instructions got\n%s\nexpected\n%s", tt.input, bytecode.Instructions, expected)
+		}
+
+		if len(bytecode.Constants) != len(tt.constants) {
+			t.Errorf("%q: len(constants) got %d, expected %d", tt.input, len(bytecode.Constants), len(tt.constants))
+			continue
+		}
+		for i, constant := range bytecode.Constants {
+			got := constant.Inspect()
+			if fn, ok := constant.(*object.CompiledFunction); ok {
+				got = fn.Instructions.String()
+			}
+			if got != tt.constants[i] {
+				t.Errorf("%q: constants[%d] got %q, expected %q", tt.input, i, got, tt.constants[i])
+			}
+		}
+	}
+}
+
+func TestSymbolTable(t *testing.T) {
+	global := NewSymbolTable()
+	a := global.Define("a")
+	if a != (Symbol{Name: "a", Scope: GlobalScope, Index: 0}) {
+		t.Errorf("a got %+v", a)
+	}
+	// 再定義は同じ位置を使う
+	if again := global.Define("a"); again != a {
+		t.Errorf("redefined a got %+v, expected %+v", again, a)
+	}
+
+	local := NewEnclosedSymbolTable(global)
+	b := local.Define("b")
+	nested := NewEnclosedSymbolTable(local)
+	nested.DefineFunctionName("f")
+	c := nested.Define("c")
+
+	tests := []struct {
+		table    *SymbolTable
+		name     string
+		expected Symbol
+	}{
+		{local, "a", Symbol{Name: "a", Scope: GlobalScope, Index: 0}},
+		{local, "b", b},
+		{nested, "a", Symbol{Name: "a", Scope: GlobalScope, Index: 0}},
+		{nested, "b", Symbol{Name: "b", Scope: FreeScope, Index: 0}},
+		{nested, "c", c},
+		{nested, "f", Symbol{Name: "f", Scope: FunctionScope, Index: 0}},
+	}
+
+	for _, tt := range tests {
+		sym, ok := tt.table.Resolve(tt.name)
+		if !ok {
+			t.Errorf("name %s not resolvable", tt.name)
+			continue
+		}
+		if sym != tt.expected {
+			t.Errorf("Resolve(%s) got %+v, expected %+v", tt.name, sym, tt.expected)
+		}
+	}
+
+	if len(nested.FreeSymbols) != 1 || nested.FreeSymbols[0] != b {
+		t.Errorf("nested.FreeSymbols got %+v, expected [%+v]", nested.FreeSymbols, b)
+	}
+
+	if _, ok := nested.Resolve("d"); ok {
+		t.Errorf("name d resolved, expected to be undefined")
+	}
+}
+
+func TestSymbolTableBlock(t *testing.T) {
+	global := NewSymbolTable()
+	a := global.Define("a")
+
+	// ブロックの中で宣言した名前はメインのフレームのローカル変数とし、外側の名前を隠す
+	global.EnterBlock()
+	inner := global.Define("a")
+	if inner != (Symbol{Name: "a", Scope: LocalScope, Index: 0}) {
+		t.Errorf("inner a got %+v", inner)
+	}
+	if again := global.Define("a"); again != inner {
+		t.Errorf("redefined inner a got %+v, expected %+v", again, inner)
+	}
+	b := global.Define("b")
+	global.LeaveBlock()
+
+	if sym, ok := global.Resolve("a"); !ok || sym != a {
+		t.Errorf("Resolve(a) got %+v, expected %+v", sym, a)
+	}
+	if _, ok := global.Resolve("b"); ok {
+		t.Errorf("name b resolved, expected to be undefined")
+	}
+
+	if names := global.names(); len(names) != 1 {
+		t.Errorf("global.names() got %q", names)
+	}
+	names := global.mainLocalNames()
+	if len(names) != 2 || names[inner.Index] != "a" || names[b.Index] != "b" {
+		t.Errorf("global.mainLocalNames() got %q", names)
+	}
+}
@@ -0,0 +1,241 @@
+package compiler
+
+import "sort"
+
+type SymbolScope string
+
+const (
+	GlobalScope   SymbolScope = "GLOBAL"
+	LocalScope    SymbolScope = "LOCAL"
+	FreeScope     SymbolScope = "FREE"     // 外側の関数のローカル変数
+	FunctionScope SymbolScope = "FUNCTION" // 定義中の関数自身
+)
+
+type Symbol struct {
+	Name  string
+	Scope SymbolScope
+	Index int
+	Cell  bool // 値をobject.Cellに格納するローカル変数または自由変数
+	Const bool // constで宣言された変数
+}
+
+// 関数ごとに作られ、外側の関数のシンボルテーブルを参照する
+type SymbolTable struct {
+	Outer *SymbolTable
+
+	store          map[string]Symbol
+	numDefinitions int
+	cells          map[string]bool // 関数の中で代入される名前
+	declared       map[string]bool // このスコープで宣言された名前
+	strict         bool            // 最も外側のシンボルテーブルのみが保持する
+	slotNames      []string        // 添字に対応する名前
+	blocks         []*blockScope
+	blockNames     []string // 最も外側のループの本体で宣言したローカル変数の名前（メインのフレームに置く）
+
+	FreeSymbols []Symbol // 捕捉した外側の変数（Indexは外側での位置）
+}
+
+// ループの本体のスコープ。
+// ブロックの中で宣言した名前には新しい位置を割り当て、ブロックを出ると外側の名前に戻す。
+// 最も外側のシンボルテーブルでは、グローバル変数ではなくメインのフレームのローカル変数とする。
+type blockScope struct {
+	shadowed map[string]*Symbol // 隠した外側のシンボル（外側にない場合はnil）
+	declared map[string]bool    // ブロックの外側で宣言された名前
+}
+
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{store: make(map[string]Symbol), declared: make(map[string]bool)}
+}
+
+func NewEnclosedSymbolTable(outer *SymbolTable) *SymbolTable {
+	s := NewSymbolTable()
+	s.Outer = outer
+	return s
+}
+
+// 同じスコープで定義済みの名前は同じ位置を再利用する（letによる再束縛）
+func (s *SymbolTable) Define(name string) Symbol {
+	if b := s.currentBlock(); b != nil && !s.declared[name] {
+		if _, ok := b.shadowed[name]; !ok {
+			var outer *Symbol
+			if sym, ok := s.store[name]; ok {
+				outer = &sym
+			}
+			b.shadowed[name] = outer
+		}
+		delete(s.store, name)
+	}
+
+	var symbol Symbol
+	if s.Outer == nil && s.InBlock() {
+		symbol = s.reserveBlockLocal(name)
+	} else {
+		symbol = s.reserve(name)
+	}
+	symbol.Const = false
+
+	s.store[name] = symbol
+	s.declared[name] = true
+
+	return symbol
+}
+
+// 再代入できない変数を定義する
+func (s *SymbolTable) DefineConst(name string) Symbol {
+	symbol := s.Define(name)
+	symbol.Const = true
+
+	s.store[name] = symbol
+
+	return symbol
+}
+
+// 宣言せずに名前の位置を確保する（後から定義されるかもしれないグローバル変数の参照用）
+func (s *SymbolTable) reserve(name string) Symbol {
+	if sym, ok := s.store[name]; ok && (sym.Scope == GlobalScope || sym.Scope == LocalScope) {
+		return sym
+	}
+
+	symbol := Symbol{Name: name, Index: s.numDefinitions}
+	if s.Outer == nil {
+		symbol.Scope = GlobalScope
+	} else {
+		symbol.Scope = LocalScope
+		symbol.Cell = s.cells[name]
+	}
+
+	s.store[name] = symbol
+	s.numDefinitions += 1
+	s.slotNames = append(s.slotNames, name)
+
+	return symbol
+}
+
+// 最も外側のループの本体で宣言した名前のローカル変数の位置を確保する
+func (s *SymbolTable) reserveBlockLocal(name string) Symbol {
+	if sym, ok := s.store[name]; ok && sym.Scope == LocalScope {
+		return sym
+	}
+
+	symbol := Symbol{Name: name, Scope: LocalScope, Index: len(s.blockNames), Cell: s.cells[name]}
+
+	s.store[name] = symbol
+	s.blockNames = append(s.blockNames, name)
+
+	return symbol
+}
+
+// ブロックのスコープに入る
+func (s *SymbolTable) EnterBlock() {
+	s.blocks = append(s.blocks, &blockScope{shadowed: make(map[string]*Symbol), declared: s.declared})
+	s.declared = make(map[string]bool)
+}
+
+// ブロックのスコープを出て、ブロックの中で宣言した名前を外側のシンボルに戻す
+func (s *SymbolTable) LeaveBlock() {
+	b := s.currentBlock()
+	s.blocks = s.blocks[:len(s.blocks)-1]
+
+	for name, outer := range b.shadowed {
+		if outer != nil {
+			s.store[name] = *outer
+		} else {
+			delete(s.store, name)
+		}
+	}
+	s.declared = b.declared
+}
+
+// ループの本体をコンパイル中であればtrueを返す
+func (s *SymbolTable) InBlock() bool {
+	return len(s.blocks) > 0
+}
+
+func (s *SymbolTable) currentBlock() *blockScope {
+	if len(s.blocks) == 0 {
+		return nil
+	}
+	return s.blocks[len(s.blocks)-1]
+}
+
+// このスコープで宣言済みであればtrueを返す（外側のスコープは含まない）
+func (s *SymbolTable) Declared(name string) bool {
+	return s.declared[name]
+}
+
+// 同じスコープでの名前の再宣言をエラーとするか（最も外側のシンボルテーブルに設定されたものを使用する）
+func (s *SymbolTable) Strict() bool {
+	return s.root().strict
+}
+
+func (s *SymbolTable) SetStrict(strict bool) {
+	s.strict = strict
+}
+
+func (s *SymbolTable) DefineFunctionName(name string) Symbol {
+	symbol := Symbol{Name: name, Index: 0, Scope: FunctionScope}
+	s.store[name] = symbol
+	return symbol
+}
+
+func (s *SymbolTable) defineFree(original Symbol) Symbol {
+	s.FreeSymbols = append(s.FreeSymbols, original)
+
+	symbol := Symbol{Name: original.Name, Index: len(s.FreeSymbols) - 1, Scope: FreeScope, Cell: original.Cell, Const: original.Const}
+	s.store[original.Name] = symbol
+
+	return symbol
+}
+
+// 外側の関数のローカル変数は自由変数として捕捉する
+func (s *SymbolTable) Resolve(name string) (Symbol, bool) {
+	sym, ok := s.store[name]
+	if ok || s.Outer == nil {
+		return sym, ok
+	}
+
+	sym, ok = s.Outer.Resolve(name)
+	if !ok {
+		return sym, ok
+	}
+
+	if sym.Scope == GlobalScope {
+		return sym, ok
+	}
+
+	return s.defineFree(sym), true
+}
+
+// 最も外側（グローバル）のシンボルテーブル
+func (s *SymbolTable) root() *SymbolTable {
+	for s.Outer != nil {
+		s = s.Outer
+	}
+	return s
+}
+
+// 添字に対応するグローバル変数またはローカル変数の名前（ブロックを出た変数を含む）
+func (s *SymbolTable) names() []string {
+	names := make([]string, len(s.slotNames))
+	copy(names, s.slotNames)
+	return names
+}
+
+// グローバル変数の名前を昇順で返す（参照のみで定義されていない名前を含む）
+func (s *SymbolTable) GlobalNames() []string {
+	names := []string{}
+	for name, sym := range s.root().store {
+		if sym.Scope == GlobalScope {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// 添字に対応するメインのフレームのローカル変数の名前
+func (s *SymbolTable) mainLocalNames() []string {
+	names := make([]string, len(s.blockNames))
+	copy(names, s.blockNames)
+	return names
+}
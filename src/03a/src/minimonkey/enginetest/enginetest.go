@@ -0,0 +1,479 @@
+// 評価器（evalutor）と仮想マシン（vm）で共通のテストケース。
+// 同じ入力に対して両者が同じ結果となることを確かめるため、ケースはここにだけ追加する。
+package enginetest
+
+import (
+	"minimonkey/object"
+	"strings"
+	"testing"
+)
+
+// 入力を評価した結果のInspect()がExpectedと一致することを確かめる
+type Case struct {
+	Input    string
+	Expected string
+}
+
+// 入力を評価する関数（エラーは*object.Errorとして返す）
+type EvalFunc func(input string) object.Object
+
+// すべてのケースを名前ごとのサブテストとして実行する
+func Run(t *testing.T, eval EvalFunc) {
+	for _, table := range Tables {
+		t.Run(table.Name, func(t *testing.T) {
+			for _, tt := range table.Cases {
+				evaluted := eval(tt.Input)
+
+				if evaluted.Inspect() != tt.Expected {
+					t.Errorf("%q: evaluted.Inspect() got %q, expected %q", tt.Input, evaluted.Inspect(), tt.Expected)
+				}
+			}
+		})
+	}
+}
+
+var Tables = []struct {
+	Name  string
+	Cases []Case
+}{
+	{"IntegerLiteral", []Case{
+		{"5", "5"},
+		{"10", "10"},
+	}},
+	{"PrefixExpression", []Case{
+		{"-5", "-5"},
+		{"-10", "-10"},
+	}},
+	{"InfixExpression", []Case{
+		{"5 + 5 + 5 + 5 - 10", "10"},
+		{"2 * 2 * 2 * 2 * 2", "32"},
+		{"-50 + 100 + -50", "0"},
+		{"5 * 2 + 10", "20"},
+		{"5 + 2 * 10", "25"},
+		{"20 + 2 * -10", "0"},
+		{"50 / 2 * 2 + 10", "60"},
+		{"2 * (5 + 10)", "30"},
+		{"3 * 3 * 3 + 10", "37"},
+		{"3 * (3 * 3) + 10", "37"},
+		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", "50"},
+	}},
+	{"BooleanExpression", []Case{
+		{"true", "true"},
+		{"false", "false"},
+		{"1 < 2", "true"},
+		{"1 > 2", "false"},
+		{"1 < 1", "false"},
+		{"1 <= 1", "true"},
+		{"2 >= 3", "false"},
+		{"1 == 1", "true"},
+		{"1 != 1", "false"},
+		{"1 == 2", "false"},
+		{"true == true", "true"},
+		{"true != false", "true"},
+		{"(1 < 2) == true", "true"},
+		{"(1 > 2) == true", "false"},
+		{"1 == true", "false"},
+		{"!true", "false"},
+		{"!false", "true"},
+		{"!5", "false"},
+		{"!!true", "true"},
+		{"!!5", "true"},
+		{"!fn(){}()", "true"},
+	}},
+	{"IfExpression", []Case{
+		{"if (true) { 10 }", "10"},
+		{"if (false) { 10 }", "null"},
+		{"if (1) { 10 }", "10"},
+		{"if (1 < 2) { 10 }", "10"},
+		{"if (1 > 2) { 10 }", "null"},
+		{"if (1 > 2) { 10 } else { 20 }", "20"},
+		{"if (1 < 2) { 10 } else { 20 }", "10"},
+		{"if (1 < 2) { }", "null"},
+		{"if (1 < 2) { if (true) { return 10 }; 1 }; 2", "10"},
+		{"let max = fn(a, b) { if (a > b) { a } else { b } }; max(3, 7)", "7"},
+		{"let fact = fn(n) { if (n <= 1) { return 1 }; n * fact(n - 1) }; fact(5)", "120"},
+	}},
+	{"StringExpression", []Case{
+		{`"hello world"`, "hello world"},
+		{`"hello" + " " + "world"`, "hello world"},
+		{`"\u{3053}\u{3093}" + "\u{306B}\u{3061}\u{306F}"`, "こんにちは"},
+		{`let greet = fn(name) { "hello, " + name }; greet("monkey")`, "hello, monkey"},
+		{`"a" == "a"`, "true"},
+		{`"a" == "b"`, "false"},
+		{`"a" != "b"`, "true"},
+		{`"a" + "b" == "ab"`, "true"},
+		{`"1" == 1`, "false"},
+	}},
+	{"LetStatement", []Case{
+		{"let a = 5; a;", "5"},
+		{"let a = 5 * 5; a;", "25"},
+		{"let a = 5; let b = a; b;", "5"},
+		{"let 値 = 5; let 二倍 = 値 * 2; 二倍;", "10"},
+		{"let a = 5; let b = a; let c = a + b + 5; c;", "15"},
+	}},
+	{"AssignStatement", []Case{
+		{"let a = 1; a = 2; a", "2"},
+		{"let a = 1; a = a + 1", "2"},
+		{"let a = 10; a += 5; a -= 3; a *= 2; a /= 4; a", "6"},
+		{`let s = "a"; s += "b"; s`, "ab"},
+		{"let a = 1.5; a *= 2; a", "3.0"},
+		{"let counter = fn() { let n = 0; fn() { n += 1 } }; let c = counter(); c(); c(); c()", "3"},
+		{"let total = 0; let add = fn(x) { total += x }; add(1); add(2); total", "3"},
+		{"let f = fn(x) { x = x * 2; x }; let y = 5; f(y) + y", "15"},
+		{"let f = fn(x) { let g = fn() { x += 1 }; g(); g(); x }; f(1)", "3"},
+		{"let a = 1; let f = fn() { let a = 10; a = 20; a }; f() + a", "21"},
+		{"let f = fn() { f = 5; 1 }; f() + f", "6"},
+		{"x = 1", "ERROR: 1:1: assignment to undefined variable: x"},
+		{"let f = fn() { y = 1 }; f()", "ERROR: 1:16: assignment to undefined variable: y"},
+		{"x += 1", "ERROR: 1:1: identifier not found: x"},
+		{`let s = "a"; s -= "b"`, "ERROR: 1:14: unknown operator STRING - STRING"},
+		{"let a = 1; a /= 0", "ERROR: 1:12: division by zero"},
+		{"let f = fn(c) { if (c) { let v = 1 }; v = 2; v }; f(false)", "ERROR: 1:39: assignment to undefined variable: v"},
+		{"let f = fn() { let g = fn(n) { if (n == 0) { g = 0; 1 } else { g(n - 1) } }; g(3) + g }; f()", "1"},
+		{"let f = fn() { let a = 1; let g = fn() { fn() { a *= 10 } }; g()(); g()(); a }; f()", "100"},
+	}},
+	{"ConstStatement", []Case{
+		{"const a = 5; a", "5"},
+		{"const a = 5; let f = fn() { a * 2 }; f()", "10"},
+		{"let a = 1; let a = 2; a", "2"},
+		{"let a = 1; const a = 2; a", "2"},
+		{"const a = 1; let f = fn() { const a = 2; a }; f() + a", "3"},
+		{"const f = fn(n) { if (n == 0) { 0 } else { n + f(n - 1) } }; f(3)", "6"},
+		{"let f = fn() { const a = 1; a }; f() + f()", "2"},
+		{"const a = 1; a = 2", "ERROR: 1:14: cannot assign to constant: a"},
+		{"const a = 1; a += 2", "ERROR: 1:14: cannot assign to constant: a"},
+		{"const a = 1; let a = 2", "ERROR: 1:14: cannot redeclare constant: a"},
+		{"const a = 1; const a = 2", "ERROR: 1:14: cannot redeclare constant: a"},
+		{"let f = fn() { const a = 1; let g = fn() { a = 2 }; g() }; f()", "ERROR: 1:44: cannot assign to constant: a"},
+		{"let f = fn(x) { const y = x; let y = 2 }; f(1)", "ERROR: 1:30: cannot redeclare constant: y"},
+		// 実行されない分岐の中のものも評価の前にエラーとする
+		{"const x = 1; if (false) { x = 2 }", "ERROR: 1:27: cannot assign to constant: x"},
+		{"let f = fn() { const a = 1; if (false) { let a = 2 } }; 1", "ERROR: 1:42: cannot redeclare constant: a"},
+		{"const a = 1; let f = fn(n = fn() { a = 2 }) { n }; 1", "ERROR: 1:36: cannot assign to constant: a"},
+		{"const a = 1; for (i in 0) { let a = 2; a = 3 }; a", "1"},
+	}},
+	{"Loops", []Case{
+		{"let n = 0; while (n < 5) { n += 1 }; n", "5"},
+		{"let n = 0; while (true) { n += 1; if (n == 3) { break } }; n", "3"},
+		{"let n = 0; let odd = 0; while (n < 6) { n += 1; if (n % 2 == 0) { continue }; odd += n }; odd", "9"},
+		{"let s = 0; for (x in [1, 2, 3]) { s += x }; s", "6"},
+		{"let s = 0; for (i in 5) { s += i }; s", "10"},
+		{"let s = 0; for (i in -1) { s += 1 }; s", "0"},
+		{`let s = ""; for (c in "値段ab") { s = c + s }; s`, "ba段値"},
+		{`let ks = []; for (k in {"b": 1, "a": 2}) { ks = push(ks, k) }; ks`, `["b", "a"]`},
+		{"let s = 0; for (i in 10) { if (i % 2 == 0) { continue }; if (i > 7) { break }; s += i }; s", "16"},
+		{"let n = 0; for (i in 3) { for (j in 3) { if (j == i) { continue }; if (j > 1) { break }; n += 1 } }; n", "4"},
+		{"let f = fn(xs) { for (x in xs) { if (x > 2) { return x * 10 } }; -1 }; [f([1, 2, 3]), f([])]", "[30, -1]"},
+		{"let f = fn() { let t = 0; for (i in 4) { let d = i * 2; t += d }; t }; f()", "12"},
+		{"let fs = []; let f = fn() { for (i in 3) { fs = push(fs, fn() { i }) } }; f(); [fs[0](), fs[2]()]", "[0, 2]"},
+		{"let x = 10; for (x in [1, 2]) { let y = x }; x", "10"},
+		{"let n = 0; while (n < 3) { const c = n; let d = c; n += 1 }; n", "3"},
+		{"for (x in []) { x }", "null"},
+		{"while (false) { 1 }", "null"},
+		{"for (x in true) { x }", "ERROR: 1:11: cannot iterate over BOOLEAN"},
+		{"for (x in 99999999999999999999) { x }", "ERROR: 1:11: integer range too large: 99999999999999999999"},
+		{"while (1 + true) { 1 }", "ERROR: 1:8: unknown operator INTEGER + BOOLEAN"},
+		{"for (x in [1]) { let y = x; y }; y", "ERROR: 1:34: identifier not found: y"},
+		// 本体の変数は繰り返しごとに別の変数となる
+		{"let r = []; for (x in 0..<3) { let y = x * 10; r = push(r, fn() { y }) }; [r[0](), r[1](), r[2]()]", "[0, 10, 20]"},
+		{"let f = fn() { let r = []; for (x in 0..<3) { let y = x; r = push(r, fn() { y }); y += 100 }; r }; let r = f(); [r[0](), r[1](), r[2]()]", "[100, 101, 102]"},
+		{"let fs = []; for (i in 3) { fs = push(fs, fn() { i }); i += 10 }; [fs[0](), fs[2]()]", "[10, 12]"},
+		{"let n = 0; let fs = []; while (n < 2) { let c = 0; fs = push(fs, fn() { c += 1; c }); n += 1 }; [fs[0](), fs[0](), fs[1]()]", "[1, 2, 1]"},
+		{"let y = 5; let z = 0; for (i in 1) { let y = y + 1; z = y }; [y, z]", "[5, 6]"},
+		{"let r = 0; for (i in 2) { let g = fn(n) { if (n == 0) { 0 } else { g(n - 1) + 1 } }; r += g(2); g = 0 }; r", "4"},
+	}},
+	{"Range", []Case{
+		{"1..5", "1..5"},
+		{"0..<3", "0..<3"},
+		{"[len(1..5), len(0..<3), len(3..1), len(3..<3)]", "[5, 3, 0, 0]"},
+		{"let s = 0; for (i in 1..4) { s += i }; s", "10"},
+		{"let s = 0; for (i in 0..<4) { s += i }; s", "6"},
+		{"let n = 3; let xs = []; for (i in 0..n-1) { xs = push(xs, i) }; xs", "[0, 1, 2]"},
+		{"let n = 0; for (i in 5..1) { n += 1 }; n", "0"},
+		{"let s = 0; for (i in 9223372036854775806..9223372036854775807) { s += 1 }; s", "2"},
+		{"take(0..<10000000000000, 3)", "[0, 1, 2]"},
+		{"take(map(1..3, fn(x) { x * x }), 5)", "[1, 4, 9]"},
+		{"take(filter(0..<100000000000, fn(x) { x % 7 == 3 }), 3)", "[3, 10, 17]"},
+		{"let k = 10; take(map([1, 2], fn(x) { x + k }), 2)", "[11, 12]"},
+		{`take(map("ab", fn(c) { c + c }), 2)`, `["aa", "bb"]`},
+		{"take(map(3, len), 1)", "ERROR: 1:1: argument to `len` not supported, got INTEGER"},
+		{"let it = map(1..3, fn(x) { x }); [take(it, 2), take(it, 2)]", "[[1, 2], [3]]"},
+		{"let s = 0; for (x in filter(1..10, fn(x) { x % 3 == 0 })) { s += x }; s", "18"},
+		{"type(map([], fn(x) { x }))", "ITERATOR"},
+		{"1..true", "ERROR: 1:1: unknown operator INTEGER .. BOOLEAN"},
+		{"0..99999999999999999999", "ERROR: 1:1: range bound out of range: 99999999999999999999"},
+		{"map(1..3, 1)", "ERROR: 1:1: argument to `map` not supported, got INTEGER"},
+		{"filter(true, fn(x) { x })", "ERROR: 1:1: argument to `filter` not supported, got BOOLEAN"},
+		{"take(1..3, -1)", "ERROR: 1:1: negative count to `take`: -1"},
+		{"take(map(1..3, fn(x) { x + true }), 2)", "ERROR: 1:24: unknown operator INTEGER + BOOLEAN"},
+	}},
+	{"FunctionParameters", []Case{
+		{"let f = fn(a, b = 2) { [a, b] }; [f(1), f(1, 3)]", "[[1, 2], [1, 3]]"},
+		{"let f = fn(a, b = a * 10) { b }; f(2)", "20"},
+		{"let n = 1; let f = fn(a = n) { a }; n = 5; f()", "5"},
+		{"let f = fn(a, ...rest) { [a, rest] }; [f(1), f(1, 2, 3)]", "[[1, []], [1, [2, 3]]]"},
+		{"let f = fn(a, b = 2, c = 3) { [a, b, c] }; f(1, c: 30)", "[1, 2, 30]"},
+		{"let f = fn(a, b) { a - b }; f(b: 1, a: 10)", "9"},
+		{"let f = fn(...xs) { len(xs) }; [f(...[1, 2], 3, ...1..3), f(...[])]", "[6, 0]"},
+		{"let f = fn(a, b, c) { a + b + c }; f(...[1, 2], c: 3)", "6"},
+		{"let f = fn(a, b) { a * b }; take(map(1..3, fn(x) { f(x, b: 2) }), 3)", "[2, 4, 6]"},
+		{"push(...[[1], 2])", "[1, 2]"},
+		// 展開の前後で引数の数が変わらない
+		{"let f = fn(x) { x }; f(...[7])", "7"},
+		{"let f = fn(a, b) { a + b }; f(1, ...[7])", "8"},
+		{"len(...[[1, 2]])", "2"},
+		{"let counter = fn(n = 0) { fn() { n += 1; n } }; let c = counter(10); c(); c()", "12"},
+		{"let f = fn(a = fn() { 1 }, ...r) { a() + len(r) }; f()", "1"},
+		{"let sum = fn(n, acc = 0) { if (n == 0) { acc } else { sum(n - 1, acc: acc + n) } }; sum(10000)", "50005000"},
+		{"let f = fn(a, b = 1) { a }; f", "fn(a,b = 1){ a; }"},
+		{"let f = fn(a, b) { a }; f(1, 2, 3)", "ERROR: 1:25: wrong number of arguments to `f(a, b)`: got 3, expected 2"},
+		{"let f = fn(a, b = 1) { a }; f()", "ERROR: 1:29: wrong number of arguments to `f(a, b = 1)`: got 0, expected 1 to 2"},
+		{"let f = fn(a, ...r) { a }; f()", "ERROR: 1:28: wrong number of arguments to `f(a, ...r)`: got 0, expected at least 1"},
+		{"fn(a, b) { a }(b: 1)", "ERROR: 1:1: missing argument `a` to `fn(a, b)`"},
+		{"let f = fn(a) { a }; f(1, a: 2)", "ERROR: 1:22: multiple values for argument `a` to `f(a)`"},
+		{"let f = fn(a, ...r) { a }; f(1, r: 2)", "ERROR: 1:28: unexpected keyword argument `r` to `f(a, ...r)`"},
+		{"len(x: [1])", "ERROR: 1:1: unexpected keyword argument `x` to `len`"},
+		{"let f = fn(a) { a }; f(...true)", "ERROR: 1:22: cannot spread BOOLEAN"},
+		{"let f = fn(a = 1 + true) { a }; f()", "ERROR: 1:16: unknown operator INTEGER + BOOLEAN"},
+	}},
+	{"EmptyStatement", []Case{
+		{";", "null"},
+		{"; 10;", "10"},
+		{"10; ;", "null"},
+	}},
+	{"ReturnStatement", []Case{
+		{"return 10; 9;", "10"},
+		{"return 2 * 5; 9;", "10"},
+		{"9; return 2 * 5; 9;", "10"},
+		{"return;", "null"},
+		{"return; return 10", "null"},
+	}},
+	{"CallExpression", []Case{
+		{"let identity = fn(x) { x }; identity(5);", "5"},
+		{"let identity = fn(x) { return x }; identity(5);", "5"},
+		{"let double = fn(x) { x * 2 }; double(5);", "10"},
+		{"let add = fn(x, y) { x + y }; add(5, 5);", "10"},
+		{"let add = fn(x, y) { x + y }; add(5 + 5, add(5, 5));", "20"},
+		{"let x = 5; let y = fn(x) { x }(10); x;", "5"},
+		{"fn(x) { x }(5)", "5"},
+		{"fn() {}()", "null"},
+		{"fn() { return }()", "null"},
+		{"let callTwoTimes = fn(x, func) { func(func(x)) }; callTwoTimes(3, fn(x) { x + 1 });", "5"}, // high-oder function
+		{"let newAdder = fn(x) { fn(n) { x + n } }; let addTwo = newAdder(2); addTwo(2);", "4"},      // closure
+		{"let f = fn() { fn() { return 1 }(); 2 }; f();", "2"},                                       // return does not escape the inner function
+		{"let f = fn(x) { let g = fn() { return x }; return g() + 1; 0 }; f(1);", "2"},
+		{"let x = 10; let f = fn() { x }; let g = fn(x) { f() }; g(1);", "10"}, // lexical scope
+	}},
+	{"RecursiveFunctions", []Case{
+		{"let fib = fn(n) { if (n < 2) { return n }; fib(n - 1) + fib(n - 2) }; fib(15)", "610"},
+		{"let f = fn() { let countdown = fn(n) { if (n == 0) { 0 } else { countdown(n - 1) } }; countdown(3) }; f()", "0"},
+		{"let sum = fn(n) { if (n == 0) { 0 } else { n + sum(n - 1) } }; sum(10000)", "50005000"},
+		// 関数の定義より後に定義されたグローバル変数を参照する
+		{"let isEven = fn(n) { if (n == 0) { true } else { isOdd(n - 1) } }; let isOdd = fn(n) { if (n == 0) { false } else { isEven(n - 1) } }; if (isOdd(7)) { 1 } else { 0 }", "1"},
+	}},
+	{"ArrayLiteral", []Case{
+		{"[]", "[]"},
+		{"[1, 2 * 2, 3 + 3]", "[1, 4, 6]"},
+		{`["a", true, [1]]`, `["a", true, [1]]`},
+	}},
+	{"HashLiteral", []Case{
+		{"{}", "{}"},
+		{`let two = "two"; {"one": 10 - 9, two: 1 + 1, "thr" + "ee": 6 / 2, 4: 4, true: 5, false: 6}`, `{"one": 1, "two": 2, "three": 3, 4: 4, true: 5, false: 6}`},
+	}},
+	{"IndexExpression", []Case{
+		{"[1, 2, 3][0]", "1"},
+		{"[1, 2, 3][1 + 1]", "3"},
+		{"let i = 0; [1][i]", "1"},
+		{"let a = [1, 2, 3]; a[0] + a[1] + a[2]", "6"},
+		{"let a = [[1, 2], [3, 4]]; a[1][0]", "3"},
+		{"let a = [fn(x) { x * 2 }]; a[0](4)", "8"},
+		{`{"foo": 5}["foo"]`, "5"},
+		{`{"foo": 5}["bar"]`, "null"},
+		{`let key = "foo"; {"foo": 5}[key]`, "5"},
+		{`{}["foo"]`, "null"},
+		{"{5: 5}[5]", "5"},
+		{"{true: 5}[true]", "5"},
+		{"{false: 5}[false]", "5"},
+		{`{"a": 1, "a": 2}["a"]`, "2"},
+	}},
+	{"BuiltinFunctions", []Case{
+		{`len("")`, "0"},
+		{`len("four")`, "4"},
+		{`len("こんにちは")`, "5"},
+		{`len([1, 2, 3])`, "3"},
+		{`len({"a": 1})`, "1"},
+		{`len(1)`, "ERROR: 1:1: argument to `len` not supported, got INTEGER"},
+		{`len("one", "two")`, "ERROR: 1:1: wrong number of arguments to `len`: got 2, expected 1"},
+		{`first([1, 2, 3])`, "1"},
+		{`first([])`, "null"},
+		{`first(1)`, "ERROR: 1:1: argument to `first` not supported, got INTEGER"},
+		{`last([1, 2, 3])`, "3"},
+		{`last([])`, "null"},
+		{`rest([1, 2, 3])`, "[2, 3]"},
+		{`rest([1])`, "[]"},
+		{`rest([])`, "null"},
+		{`push([], 1)`, "[1]"},
+		{`let a = [1]; push(a, 2); a`, "[1]"},
+		{`push(1, 1)`, "ERROR: 1:1: argument to `push` not supported, got INTEGER"},
+		{`push([1])`, "ERROR: 1:1: wrong number of arguments to `push`: got 1, expected 2"},
+		{`puts()`, "null"},
+		{`type(1)`, "INTEGER"},
+		{`type("a") == "STRING"`, "true"},
+		{`type(len)`, "BUILTIN"},
+		{`let len = fn(x) { 0 }; len([1])`, "0"},
+	}},
+	{"TailCall", []Case{
+		{"let loop = fn(n, acc) { if (n == 0) { acc } else { loop(n - 1, acc + n) } }; loop(1000, 0)", "500500"},
+		{"let loop = fn(n, acc) { if (n == 0) { return acc }; return loop(n - 1, acc + 1) }; loop(1000, 0)", "1000"},
+		{"let loop = fn(n) { if (n > 0) { return loop(n - 1) }; 42 }; loop(1000)", "42"},
+		{"let even = fn(n) { if (n == 0) { 1 } else { odd(n - 1) } }; let odd = fn(n) { if (n == 0) { 0 } else { even(n - 1) } }; even(1001)", "0"},
+		{"let f = fn(n) { n * 2 }; let g = fn(n) { f(n + 1) }; g(1) + g(2)", "10"},
+		{"let f = fn(n) { n }; return f(5); 0", "5"},
+		{"let f = fn() { len([1, 2]) }; f()", "2"},
+	}},
+	{"ErrorHandling", []Case{
+		{"foobar", "ERROR: 1:1: identifier not found: foobar"},
+		{"let v = fn(){}(); v + 1;", "ERROR: 1:19: unknown operator NULL + INTEGER"},
+		{"let add = fn(x, y) { x + y }; add(1);", "ERROR: 1:31: wrong number of arguments to `add(x, y)`: got 1, expected 2"},
+		{"let one = fn() { 1 }; one(1);", "ERROR: 1:23: wrong number of arguments to `one()`: got 1, expected 0"},
+		{"let x = 1; x(1);", "ERROR: 1:12: not a function: INTEGER"},
+		{"let f = fn() { foobar; 10 }; f();", "ERROR: 1:16: identifier not found: foobar"},
+		{"foobar; 10;", "ERROR: 1:1: identifier not found: foobar"},
+		{"-true", "ERROR: 1:1: unknown operator -BOOLEAN"},
+		{"true + false", "ERROR: 1:1: unknown operator BOOLEAN + BOOLEAN"},
+		{"1 < true", "ERROR: 1:1: unknown operator INTEGER < BOOLEAN"},
+		{"if (true + 1) { 10 }", "ERROR: 1:5: unknown operator BOOLEAN + INTEGER"},
+		{`"a" - "b"`, "ERROR: 1:1: unknown operator STRING - STRING"},
+		{`"a" + 1`, "ERROR: 1:1: unknown operator STRING + INTEGER"},
+		{"[1, 2, 3][3]", "ERROR: 1:1: index out of range: 3 (length 3)"},
+		{"[1, 2, 3][-1]", "ERROR: 1:1: index out of range: -1 (length 3)"},
+		{`[1, 2, 3]["a"]`, "ERROR: 1:1: index operator not supported: ARRAY[STRING]"},
+		{"1[0]", "ERROR: 1:1: index operator not supported: INTEGER[INTEGER]"},
+		{`{"a": 1}[[1]]`, "ERROR: 1:1: unusable as hash key: ARRAY"},
+		{`{fn(x) { x }: 1}`, "ERROR: 1:1: unusable as hash key: FUNCTION"},
+		{`{"a": foobar}`, "ERROR: 1:7: identifier not found: foobar"},
+	}},
+	{"ErrorPosition", []Case{
+		{"foobar", "ERROR: 1:1: identifier not found: foobar"},
+		{"let x = 1;\nlet y = x + true", "ERROR: 2:9: unknown operator INTEGER + BOOLEAN"},
+		{"let f = fn(x) {\n  x + y\n}\nf(1)", "ERROR: 2:7: identifier not found: y"},
+		{"len(1, 2)", "ERROR: 1:1: wrong number of arguments to `len`: got 2, expected 1"},
+		{"[1][1 + 1]", "ERROR: 1:1: index out of range: 2 (length 1)"},
+		{"let 名前 = \"猿\"; 名前 + 1", "ERROR: 1:15: unknown operator STRING + INTEGER"},
+	}},
+	{"TailCallError", []Case{
+		{"let f = fn(x) { x }; let g = fn() { f() }; g()", "ERROR: 1:37: wrong number of arguments to `f(x)`: got 0, expected 1"},
+		{"let g = fn() { len(1) }; g()", "ERROR: 1:16: argument to `len` not supported, got INTEGER"},
+		{"let g = fn(n) { if (n == 0) { n + true } else { g(n - 1) } }; g(3)", "ERROR: 1:31: unknown operator INTEGER + BOOLEAN"},
+		{"let g = fn() { return h() }; g()", "ERROR: 1:23: identifier not found: h"},
+	}},
+	{"RuntimeErrors", []Case{
+		{"let f = fn(n) { if (n == 0) { 0 } else { 1 + f(n - 1) } }; f(100000)", "ERROR: 1:46: stack overflow"},
+		{"let f = fn(c) { if (c) { let x = 1 }; x }; f(false)", "ERROR: 1:39: identifier not found: x"},
+		{"let g = fn() { y }; let y = 1; g() + true", "ERROR: 1:32: unknown operator INTEGER + BOOLEAN"},
+		{"1 / 0", "ERROR: 1:1: division by zero"},
+		{"let f = fn(x) {\n  10 % x\n}\nf(0)", "ERROR: 2:3: modulo by zero"},
+		{"-7 % 3 + 99999999999999999999 / 0", "ERROR: 1:10: division by zero"},
+	}},
+	{"BigInt", []Case{
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775807 - 2", "-9223372036854775809"},
+		{"9223372036854775807 * 3", "27670116110564327421"},
+		{"-(-9223372036854775807 - 1)", "9223372036854775808"},
+		{"(-9223372036854775807 - 1) / -1", "9223372036854775808"},
+		{"123456789012345678901234567890", "123456789012345678901234567890"},
+		{"123456789012345678901234567890 * 10 / 10", "123456789012345678901234567890"},
+		{"-123456789012345678901234567890 / 7", "-17636684144620811271604938270"},
+		{"type(99999999999999999999)", "INTEGER"},
+		{"99999999999999999999 > 9223372036854775807", "true"},
+		{"99999999999999999999 == 99999999999999999999", "true"},
+		{"99999999999999999999 - 99999999999999999998 == 1", "true"},
+		{`{99999999999999999999: "big", 1: "one"}[99999999999999999999]`, "big"},
+		{"[1, 2][99999999999999999999]", "ERROR: 1:1: index out of range: 99999999999999999999 (length 2)"},
+		{"let f = fn(n) { if (n == 0) { 1 } else { n * f(n - 1) } }; f(25)", "15511210043330985984000000"},
+		{"99999999999999999999 - 99999999999999999998", "1"},
+		{"99999999999999999999 >= 99999999999999999999", "true"},
+	}},
+	{"Modulo", []Case{
+		{"7 % 3", "1"},
+		{"-7 % 3", "-1"},
+		{"7 % -3", "1"},
+		{"1 + 7 % 4 * 2", "7"},
+		{"99999999999999999999 % 7", "1"},
+		{"1 / 0", "ERROR: 1:1: division by zero"},
+		{"1 % 0", "ERROR: 1:1: modulo by zero"},
+		{"99999999999999999999 / 0", "ERROR: 1:1: division by zero"},
+		{"let f = fn(x) {\n  10 / x\n}\nf(0)", "ERROR: 2:3: division by zero"},
+	}},
+	{"NumberLiteral", []Case{
+		{"0xff", "255"},
+		{"0XFF", "255"},
+		{"0o17", "15"},
+		{"0b1010", "10"},
+		{"0x_7fff_ffff", "2147483647"},
+		{"1_000_000", "1000000"},
+		{"0xffff_ffff_ffff_ffff_ff", "4722366482869645213695"},
+		{"-0b1", "-1"},
+		{"1_000.25", "1000.25"},
+		{"1e1_0", "10000000000.0"},
+	}},
+	{"Bitwise", []Case{
+		{"0xf0 & 0x3c", "48"},
+		{"0xf0 | 0x0f", "255"},
+		{"0xff ^ 0x0f", "240"},
+		{"~0", "-1"},
+		{"~-6", "5"},
+		{"-8 & 0xff", "248"},
+		{"1 << 10", "1024"},
+		{"-1024 >> 3", "-128"},
+		{"1 >> 100", "0"},
+		{"-1 >> 100", "-1"},
+		{"1 << 64", "18446744073709551616"},
+		{"(1 << 64) >> 63", "2"},
+		{"(1 << 64) | 1", "18446744073709551617"},
+		{"((1 << 64) - 1) & 0xff", "255"},
+		{"~(1 << 64)", "-18446744073709551617"},
+		{"1 >> 99999999999999999999", "0"},
+		{"0x12345678 >> 8 & 0xff", "86"},
+		{"1 << -1", "ERROR: 1:1: negative shift count: -1"},
+		{"1 >> -99999999999999999999", "ERROR: 1:1: negative shift count: -99999999999999999999"},
+		{"1 << 99999999999999999999", "ERROR: 1:1: shift count too large: 99999999999999999999"},
+		{"1.0 & 1", "ERROR: 1:1: unknown operator FLOAT & INTEGER"},
+		{"~true", "ERROR: 1:1: unknown operator ~BOOLEAN"},
+		{"0xf0 & 0x3c | 1", "49"},
+		{"~5", "-6"},
+		{"let f = fn(x) { x << -1 }; f(1)", "ERROR: 1:17: negative shift count: -1"},
+		{"~1.5", "ERROR: 1:1: unknown operator ~FLOAT"},
+	}},
+	{"Float", []Case{
+		{"3.14", "3.14"},
+		{"1e-9", "1e-09"},
+		{"2.5E+3", "2500.0"},
+		{"1e21", "1e+21"},
+		{"0.1 + 0.2", "0.30000000000000004"},
+		{"-1.5", "-1.5"},
+		{"1 + 0.5", "1.5"},
+		{"0.5 * 4", "2.0"},
+		{"7 / 2", "3"},
+		{"7 / 2.0", "3.5"},
+		{"7.5 % 2", "1.5"},
+		{"-7.5 % 2", "-1.5"},
+		{"99999999999999999999 * 0.5", "50000000000000000000.0"},
+		{"1e308 * 10", "ERROR: 1:1: float overflow"},
+		{"-1e308 * 10", "ERROR: 1:1: float overflow"},
+		{"let f = fn(x) {\n  x + x\n}\nf(1.5e308)", "ERROR: 2:3: float overflow"},
+		{"1" + strings.Repeat("0", 400) + " * 0.5", "ERROR: 1:1: float overflow"},
+		{"1 == 1.0", "true"},
+		{"1.5 != 1.5", "false"},
+		{"1 < 1.5", "true"},
+		{"2.5 >= 3", "false"},
+		{"type(1.0)", "FLOAT"},
+		{"1.0 / 0", "ERROR: 1:1: division by zero"},
+		{"1 % 0.0", "ERROR: 1:1: modulo by zero"},
+		{`1.5 + "a"`, "ERROR: 1:1: unknown operator FLOAT + STRING"},
+		{"{1.5: 1}", "ERROR: 1:1: unusable as hash key: FLOAT"},
+		{"-2.5 * 2", "-5.0"},
+		{"1 > 0.5", "true"},
+		{"let f = fn(x) { x / 2 }; f(3.0) + f(3)", "2.5"},
+	}},
+}
@@ -0,0 +1,282 @@
+package evalutor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"minimonkey/object"
+)
+
+var builtins map[string]*object.Builtin
+
+// putsの出力先（評価器と仮想マシンで共有する）
+var output io.Writer = os.Stdout
+
+// putsの出力先を変更する
+func SetOutput(w io.Writer) {
+	output = w
+}
+
+func init() {
+	builtins = make(map[string]*object.Builtin)
+
+	registerBuiltin("len", builtinLen)
+	registerBuiltin("first", builtinFirst)
+	registerBuiltin("last", builtinLast)
+	registerBuiltin("rest", builtinRest)
+	registerBuiltin("push", builtinPush)
+	registerBuiltin("puts", builtinPuts)
+	registerBuiltin("type", builtinType)
+	registerHigherOrder("map", builtinMap)
+	registerHigherOrder("filter", builtinFilter)
+	registerBuiltin("take", builtinTake)
+}
+
+func registerBuiltin(name string, fn func(name string, args ...object.Object) object.Object) {
+	builtins[name] = &object.Builtin{
+		Name: name,
+		Fn: func(args ...object.Object) object.Object {
+			return fn(name, args...)
+		},
+	}
+}
+
+// 引数の関数を呼び出す組み込み関数を登録する
+func registerHigherOrder(name string, fn func(name string, call object.Caller, args ...object.Object) object.Object) {
+	builtins[name] = &object.Builtin{
+		Name: name,
+		HigherOrder: func(call object.Caller, args ...object.Object) object.Object {
+			return fn(name, call, args...)
+		},
+	}
+}
+
+// 名前に対応する組み込み関数を返す
+func LookupBuiltin(name string) (*object.Builtin, bool) {
+	builtin, ok := builtins[name]
+	return builtin, ok
+}
+
+func wrongNumberOfArguments(name string, got int, expected int) *object.Error {
+	return newError("wrong number of arguments to `%s`: got %d, expected %d", name, got, expected)
+}
+
+func argumentNotSupported(name string, arg object.Object) *object.Error {
+	return newError("argument to `%s` not supported, got %s", name, arg.Type())
+}
+
+// len(<string or array or hash or range>)
+func builtinLen(name string, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArguments(name, len(args), 1)
+	}
+
+	switch arg := args[0].(type) {
+	case *object.String:
+		return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+	case *object.Array:
+		return &object.Integer{Value: int64(len(arg.Elements))}
+	case *object.Hash:
+		return &object.Integer{Value: int64(arg.Len())}
+	case *object.Range:
+		return object.NewInteger(arg.Len())
+	default:
+		return argumentNotSupported(name, arg)
+	}
+}
+
+// first(<array>)
+func builtinFirst(name string, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArguments(name, len(args), 1)
+	}
+
+	array, ok := args[0].(*object.Array)
+	if !ok {
+		return argumentNotSupported(name, args[0])
+	}
+
+	if len(array.Elements) == 0 {
+		return NULL
+	}
+
+	return array.Elements[0]
+}
+
+// last(<array>)
+func builtinLast(name string, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArguments(name, len(args), 1)
+	}
+
+	array, ok := args[0].(*object.Array)
+	if !ok {
+		return argumentNotSupported(name, args[0])
+	}
+
+	if len(array.Elements) == 0 {
+		return NULL
+	}
+
+	return array.Elements[len(array.Elements)-1]
+}
+
+// rest(<array>) 先頭を除いた新しい配列を返す
+func builtinRest(name string, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArguments(name, len(args), 1)
+	}
+
+	array, ok := args[0].(*object.Array)
+	if !ok {
+		return argumentNotSupported(name, args[0])
+	}
+
+	if len(array.Elements) == 0 {
+		return NULL
+	}
+
+	elements := make([]object.Object, len(array.Elements)-1)
+	copy(elements, array.Elements[1:])
+
+	return &object.Array{Elements: elements}
+}
+
+// push(<array>, <value>) 末尾に要素を追加した新しい配列を返す
+func builtinPush(name string, args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return wrongNumberOfArguments(name, len(args), 2)
+	}
+
+	array, ok := args[0].(*object.Array)
+	if !ok {
+		return argumentNotSupported(name, args[0])
+	}
+
+	elements := make([]object.Object, len(array.Elements), len(array.Elements)+1)
+	copy(elements, array.Elements)
+	elements = append(elements, args[1])
+
+	return &object.Array{Elements: elements}
+}
+
+// puts(<value>...)
+func builtinPuts(name string, args ...object.Object) object.Object {
+	for _, arg := range args {
+		fmt.Fprintln(output, arg.Inspect())
+	}
+
+	return NULL
+}
+
+// type(<value>)
+func builtinType(name string, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArguments(name, len(args), 1)
+	}
+
+	return &object.String{Value: string(args[0].Type())}
+}
+
+// map(<iterable>, <function>)
+// 要素に関数を適用した結果を順に返すIteratorを返す（関数は要素を取り出すときに呼び出す）
+func builtinMap(name string, call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return wrongNumberOfArguments(name, len(args), 2)
+	}
+
+	it, fn, errObj := iteratorAndFunction(name, args)
+	if errObj != nil {
+		return errObj
+	}
+
+	return object.NewIterator(func() (object.Object, bool) {
+		val, ok := it.Next()
+		if !ok || isError(val) {
+			return val, ok
+		}
+		return call(fn, val), true
+	})
+}
+
+// filter(<iterable>, <function>)
+// 関数の結果が真となる要素を順に返すIteratorを返す
+func builtinFilter(name string, call object.Caller, args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return wrongNumberOfArguments(name, len(args), 2)
+	}
+
+	it, fn, errObj := iteratorAndFunction(name, args)
+	if errObj != nil {
+		return errObj
+	}
+
+	return object.NewIterator(func() (object.Object, bool) {
+		for {
+			val, ok := it.Next()
+			if !ok || isError(val) {
+				return val, ok
+			}
+
+			res := call(fn, val)
+			if isError(res) {
+				return res, true
+			}
+			if isTruthy(res) {
+				return val, true
+			}
+		}
+	})
+}
+
+func iteratorAndFunction(name string, args []object.Object) (object.Iterator, object.Object, *object.Error) {
+	it, errObj := object.Iterate(args[0])
+	if errObj != nil {
+		return nil, nil, argumentNotSupported(name, args[0])
+	}
+
+	switch args[1].(type) {
+	case *object.Function, *object.Closure, *object.Builtin:
+	default:
+		return nil, nil, argumentNotSupported(name, args[1])
+	}
+
+	return it, args[1], nil
+}
+
+// take(<iterable>, <integer>)
+// 先頭から最大n個の要素を取り出して配列を返す（残りの要素は取り出さない）
+func builtinTake(name string, args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return wrongNumberOfArguments(name, len(args), 2)
+	}
+
+	it, errObj := object.Iterate(args[0])
+	if errObj != nil {
+		return argumentNotSupported(name, args[0])
+	}
+
+	n, ok := args[1].(*object.Integer)
+	if !ok {
+		return argumentNotSupported(name, args[1])
+	}
+	if n.Value < 0 {
+		return newError("negative count to `%s`: %d", name, n.Value)
+	}
+
+	elements := []object.Object{}
+	for int64(len(elements)) < n.Value {
+		val, ok := it.Next()
+		if !ok {
+			break
+		}
+		if isError(val) {
+			return val
+		}
+		elements = append(elements, val)
+	}
+
+	return &object.Array{Elements: elements}
+}
@@ -0,0 +1,10 @@
+package evalutor
+
+import (
+	"minimonkey/enginetest"
+	"testing"
+)
+
+func TestEngine(t *testing.T) {
+	enginetest.Run(t, testEval)
+}
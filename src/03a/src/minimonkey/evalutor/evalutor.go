@@ -2,16 +2,52 @@ package evalutor
 
 import (
 	"fmt"
+	"strings"
 
 	"minimonkey/ast"
 	"minimonkey/object"
 )
 
+// 末尾呼び出しでない関数呼び出しの深さの上限（仮想マシンのMAX_FRAMESと同じ）
+const MAX_CALL_DEPTH = 1 << 16
+
 var (
-	NULL = &object.Null{}
+	NULL     = &object.Null{}
+	TRUE     = &object.Boolean{Value: true}
+	FALSE    = &object.Boolean{Value: false}
+	BREAK    = &object.Break{}
+	CONTINUE = &object.Continue{}
 )
 
-func Eval(node ast.Node, env *object.Environment) object.Object {
+func Eval(node ast.Node, env *object.Environment) (res object.Object) {
+	// 想定外のGoのパニックで処理系全体を停止させない
+	defer func() {
+		if r := recover(); r != nil {
+			res = panicToError(r, node)
+		}
+	}()
+
+	if program, ok := node.(*ast.Program); ok {
+		if err := resolve(program, env); err != nil {
+			return err
+		}
+	}
+
+	return evalNode(node, env)
+}
+
+func evalNode(node ast.Node, env *object.Environment) object.Object {
+	res := eval(node, env)
+
+	// エラーが発生した最も内側のノードの位置を記録する
+	if err, ok := res.(*object.Error); ok && !err.Pos.IsValid() {
+		err.Pos = node.Pos()
+	}
+
+	return res
+}
+
+func eval(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 
 	// Statements
@@ -19,52 +55,133 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return evalProgram(node, env)
 
 	case *ast.ExpressionStatement:
-		return Eval(node.Expression, env)
+		return evalNode(node.Expression, env)
 
 	case *ast.LetStatement:
-		val := Eval(node.Value, env)
+		return evalLetStatement(node, env)
+
+	case *ast.AssignStatement:
+		return evalAssignStatement(node, env)
+
+	case *ast.WhileStatement:
+		return evalWhileStatement(node, env)
+
+	case *ast.ForStatement:
+		return evalForStatement(node, env)
+
+	case *ast.BreakStatement:
+		return BREAK
+
+	case *ast.ContinueStatement:
+		return CONTINUE
+
+	case *ast.EmptyStatement:
+		return NULL
+
+	case *ast.ReturnStatement:
+		if node.ReturnValue == nil {
+			return &object.ReturnValue{Value: NULL}
+		}
+		// return f(...) は関数の末尾呼び出しとなる
+		var val object.Object
+		if call, ok := node.ReturnValue.(*ast.CallExpression); ok {
+			val = evalTailExpression(call, env)
+		} else {
+			val = evalNode(node.ReturnValue, env)
+		}
 		if isError(val) {
 			return val
 		}
-		return env.Set(node.Name.Value, val)
+		if val == nil {
+			val = NULL
+		}
+		return &object.ReturnValue{Value: val}
 
-	// // TODO
-	// case *ast.EmptyStatement:
-	// case *ast.ReturnStatement:
-	// case *ast.BlockStatement:
+	case *ast.BlockStatement:
+		return evalBlockStatement(node, env)
 
 	// Expressions
 	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			return &object.BigInt{Value: node.Big}
+		}
 		return &object.Integer{Value: node.Value}
 
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
+
+	case *ast.StringLiteral:
+		return &object.String{Value: node.Value}
+
+	case *ast.Boolean:
+		return nativeBoolToBooleanObject(node.Value)
+
 	case *ast.PrefixExpression:
-		right := Eval(node.Right, env)
+		right := evalNode(node.Right, env)
 		if isError(right) {
 			return right
 		}
 		return evalPrefixExpression(node.Operator, right)
 
 	case *ast.InfixExpression:
-		left := Eval(node.Left, env)
+		left := evalNode(node.Left, env)
 		if isError(left) {
 			return left
 		}
-		right := Eval(node.Right, env)
+		right := evalNode(node.Right, env)
 		if isError(right) {
 			return right
 		}
 		return evalInfixExpression(node.Operator, left, right)
 
 	case *ast.Identifier:
-		val, ok := env.Get(node.Value)
-		if !ok {
-			return newError("identifier not found: %s", node.Value)
+		return evalIdentifier(node, env)
+
+	case *ast.IfExpression:
+		return evalIfExpression(node, env)
+
+	case *ast.FunctionLiteral:
+		params := node.Parameters
+		body := node.Body
+		return &object.Function{Parameters: params, Body: body, Env: env, Literal: node} // 定義時の環境を捕捉する（クロージャ）
+
+	case *ast.SpreadExpression:
+		val := evalNode(node.Value, env)
+		if isError(val) {
+			return val
 		}
-		return val
+		return &object.Spread{Value: val}
+
+	case *ast.KeywordArgument:
+		val := evalNode(node.Value, env)
+		if isError(val) {
+			return val
+		}
+		return &object.Keyword{Name: node.Name.Value, Value: val}
+
+	case *ast.CallExpression:
+		return evalCallExpression(node, env, false)
+
+	case *ast.ArrayLiteral:
+		elements := evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isError(elements[0]) {
+			return elements[0]
+		}
+		return &object.Array{Elements: elements}
+
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
 
-	// // TODO
-	// case *ast.FunctionLiteral:
-	// case *ast.CallExpression:
+	case *ast.IndexExpression:
+		left := evalNode(node.Left, env)
+		if isError(left) {
+			return left
+		}
+		index := evalNode(node.Index, env)
+		if isError(index) {
+			return index
+		}
+		return evalIndexExpression(left, index)
 	}
 
 	return nil
@@ -78,12 +195,114 @@ func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 	var res object.Object
 
 	for _, s := range program.Statements {
-		res = Eval(s, env)
+		res = evalNode(s, env)
+
+		switch res := res.(type) {
+		case *object.ReturnValue:
+			if tc, ok := res.Value.(*tailCall); ok {
+				return applyFunction(tc.fn, tc.args, tc.node)
+			}
+			return res.Value
+		case *object.Error:
+			return res
+		}
+	}
+
+	return res
+}
+
+// ブロック内のreturnは関数の境界まで伝播させるため、ReturnValueはアンラップしない。
+// breakとcontinueも同様にループの境界まで伝播させる。
+func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
+	var res object.Object
+
+	for _, s := range block.Statements {
+		res = evalNode(s, env)
+
+		if res != nil {
+			switch res.Type() {
+			case object.RETURN_VALUE_OBJ, object.ERROR_OBJ, object.BREAK_OBJ, object.CONTINUE_OBJ:
+				return res
+			}
+		}
 	}
 
 	return res
 }
 
+// ループの本体は繰り返しごとに新しい環境で評価する（本体で宣言した名前はループの外からは見えない）
+func evalWhileStatement(node *ast.WhileStatement, env *object.Environment) object.Object {
+	for {
+		if err := env.Context().Err(); err != nil {
+			return newError("evaluation aborted: %s", err)
+		}
+
+		condition := evalNode(node.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			return NULL
+		}
+
+		res := evalBlockStatement(node.Body, object.NewEnclosedEnvironment(env))
+		if res, done := loopResult(res); done {
+			return res
+		}
+	}
+}
+
+func evalForStatement(node *ast.ForStatement, env *object.Environment) object.Object {
+	iterable := evalNode(node.Iterable, env)
+	if isError(iterable) {
+		return iterable
+	}
+
+	it, err := object.Iterate(iterable)
+	if err != nil {
+		err.Pos = node.Iterable.Pos()
+		return err
+	}
+
+	for {
+		if err := env.Context().Err(); err != nil {
+			return newError("evaluation aborted: %s", err)
+		}
+
+		val, ok := it.Next()
+		if !ok {
+			return NULL
+		}
+		if isError(val) {
+			return val
+		}
+
+		loopEnv := object.NewEnclosedEnvironment(env)
+		loopEnv.Define(node.Variable.Value, val)
+
+		res := evalBlockStatement(node.Body, loopEnv)
+		if res, done := loopResult(res); done {
+			return res
+		}
+	}
+}
+
+// ループの本体の結果がbreak、return、エラーであればループの結果とtrueを返す
+func loopResult(res object.Object) (object.Object, bool) {
+	if res == nil {
+		return nil, false
+	}
+
+	switch res.Type() {
+	case object.BREAK_OBJ:
+		return NULL, true
+	case object.RETURN_VALUE_OBJ, object.ERROR_OBJ:
+		return res, true
+	}
+
+	return nil, false
+}
+
 func isError(obj object.Object) bool {
 	if obj != nil {
 		return obj.Type() == object.ERROR_OBJ
@@ -96,39 +315,542 @@ func evalPrefixExpression(operator string, right object.Object) object.Object {
 	switch operator {
 	case "-":
 		return evalMinusPrefixOperatorExpression(right)
+	case "!":
+		return evalBangPrefixOperatorExpression(right)
+	case "~":
+		return evalTildePrefixOperatorExpression(right)
 	default:
 		return newError("unknown operator %s%s", operator, right.Type())
 	}
 }
 
 func evalMinusPrefixOperatorExpression(val object.Object) object.Object {
-	if val.Type() != object.INTEGER_OBJ {
+	switch val.Type() {
+	case object.INTEGER_OBJ:
+		return object.NegateInteger(val)
+	case object.FLOAT_OBJ:
+		return &object.Float{Value: -val.(*object.Float).Value}
+	default:
 		return newError("unknown operator -%s", val.Type())
 	}
+}
+
+func evalBangPrefixOperatorExpression(val object.Object) object.Object {
+	return nativeBoolToBooleanObject(!isTruthy(val))
+}
 
-	v := val.(*object.Integer).Value
+func evalTildePrefixOperatorExpression(val object.Object) object.Object {
+	if val.Type() != object.INTEGER_OBJ {
+		return newError("unknown operator ~%s", val.Type())
+	}
 
-	return &object.Integer{Value: -v}
+	return object.NotInteger(val)
 }
 
 func evalInfixExpression(operator string, left object.Object, right object.Object) object.Object {
-	if left.Type() != object.INTEGER_OBJ || right.Type() != object.INTEGER_OBJ {
+	switch {
+	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
+		return evalIntegerInfixExpression(operator, left, right)
+	case object.IsNumber(left) && object.IsNumber(right):
+		return evalFloatInfixExpression(operator, left, right)
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return evalStringInfixExpression(operator, left, right)
+	// 整数以外はシングルトン（TRUE, FALSE, NULL）または同一オブジェクトかどうかで比較する
+	case operator == "==":
+		return nativeBoolToBooleanObject(left == right)
+	case operator == "!=":
+		return nativeBoolToBooleanObject(left != right)
+	default:
+		return newError("unknown operator %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// int64の範囲を超える結果はBigIntとなる
+func evalIntegerInfixExpression(operator string, left object.Object, right object.Object) object.Object {
+	switch operator {
+	case "+", "-", "*", "/", "%":
+		return object.IntegerArithmetic(operator, left, right)
+	case "&", "|", "^", "<<", ">>":
+		return object.IntegerBitwise(operator, left, right)
+	case "..", "..<":
+		return object.NewRange(operator, left, right)
+	case "==":
+		return nativeBoolToBooleanObject(object.CompareIntegers(left, right) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(object.CompareIntegers(left, right) != 0)
+	case "<":
+		return nativeBoolToBooleanObject(object.CompareIntegers(left, right) < 0)
+	case ">":
+		return nativeBoolToBooleanObject(object.CompareIntegers(left, right) > 0)
+	case "<=":
+		return nativeBoolToBooleanObject(object.CompareIntegers(left, right) <= 0)
+	case ">=":
+		return nativeBoolToBooleanObject(object.CompareIntegers(left, right) >= 0)
+	default:
 		return newError("unknown operator %s %s %s", left.Type(), operator, right.Type())
 	}
+}
 
-	lv := left.(*object.Integer).Value
-	rv := right.(*object.Integer).Value
+// 片方が整数の場合は浮動小数点数に変換して計算する
+func evalFloatInfixExpression(operator string, left object.Object, right object.Object) object.Object {
+	lv, _ := object.ToFloat(left)
+	rv, _ := object.ToFloat(right)
+
+	switch operator {
+	case "+", "-", "*", "/", "%":
+		return object.FloatArithmetic(operator, lv, rv)
+	case "==":
+		return nativeBoolToBooleanObject(lv == rv)
+	case "!=":
+		return nativeBoolToBooleanObject(lv != rv)
+	case "<":
+		return nativeBoolToBooleanObject(lv < rv)
+	case ">":
+		return nativeBoolToBooleanObject(lv > rv)
+	case "<=":
+		return nativeBoolToBooleanObject(lv <= rv)
+	case ">=":
+		return nativeBoolToBooleanObject(lv >= rv)
+	default:
+		return newError("unknown operator %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func evalStringInfixExpression(operator string, left object.Object, right object.Object) object.Object {
+	lv := left.(*object.String).Value
+	rv := right.(*object.String).Value
 
 	switch operator {
 	case "+":
-		return &object.Integer{Value: lv + rv}
-	case "-":
-		return &object.Integer{Value: lv - rv}
-	case "*":
-		return &object.Integer{Value: lv * rv}
-	case "/":
-		return &object.Integer{Value: lv / rv}
+		return &object.String{Value: lv + rv}
+	case "==":
+		return nativeBoolToBooleanObject(lv == rv)
+	case "!=":
+		return nativeBoolToBooleanObject(lv != rv)
 	default:
 		return newError("unknown operator %s %s %s", left.Type(), operator, right.Type())
 	}
 }
+
+// 同じスコープでの再宣言は、constであれば常に、letであればstrictの場合にエラーとする
+func evalLetStatement(node *ast.LetStatement, env *object.Environment) object.Object {
+	name := node.Name.Value
+
+	if env.Declared(name) {
+		if env.IsConst(name) {
+			return newError("cannot redeclare constant: %s", name)
+		}
+		if env.Strict() {
+			return newError("identifier already declared: %s", name)
+		}
+	}
+
+	val := evalNode(node.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	if node.IsConst() {
+		return env.DefineConst(name, val)
+	}
+	return env.Define(name, val)
+}
+
+// 複合代入（x += 1）は右辺より先に現在の値を取得する
+func evalAssignStatement(node *ast.AssignStatement, env *object.Environment) object.Object {
+	name := node.Name.Value
+
+	if env.IsConst(name) {
+		return newError("cannot assign to constant: %s", name)
+	}
+
+	var current object.Object
+	if node.Operator != "=" {
+		val, ok := env.Get(name)
+		if !ok {
+			return newError("identifier not found: %s", name)
+		}
+		current = val
+	}
+
+	val := evalNode(node.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	if current != nil {
+		val = evalInfixExpression(strings.TrimSuffix(node.Operator, "="), current, val)
+		if isError(val) {
+			return val
+		}
+	}
+
+	if _, ok := env.Assign(name, val); !ok {
+		return newError("assignment to undefined variable: %s", name)
+	}
+
+	return val
+}
+
+// 環境に束縛がない場合は組み込み関数を探す
+func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
+	if val, ok := env.Get(node.Value); ok {
+		return val
+	}
+
+	if builtin, ok := builtins[node.Value]; ok {
+		return builtin
+	}
+
+	return newError("identifier not found: %s", node.Value)
+}
+
+func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
+	condition := evalNode(ie.Condition, env)
+	if isError(condition) {
+		return condition
+	}
+
+	var res object.Object
+	if isTruthy(condition) {
+		res = evalNode(ie.Consequence, env)
+	} else if ie.Alternative != nil {
+		res = evalNode(ie.Alternative, env)
+	}
+
+	if res == nil {
+		return NULL
+	}
+
+	return res
+}
+
+// NULLとFALSEのみを偽とし、それ以外はすべて真とする
+func isTruthy(obj object.Object) bool {
+	switch obj {
+	case NULL, FALSE, nil:
+		return false
+	default:
+		return true
+	}
+}
+
+func nativeBoolToBooleanObject(v bool) *object.Boolean {
+	if v {
+		return TRUE
+	}
+	return FALSE
+}
+
+func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	hash := object.NewHash()
+
+	for _, pair := range node.Pairs {
+		key := evalNode(pair.Key, env)
+		if isError(key) {
+			return key
+		}
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", key.Type())
+		}
+
+		value := evalNode(pair.Value, env)
+		if isError(value) {
+			return value
+		}
+
+		hash.Set(hashKey, value)
+	}
+
+	return hash
+}
+
+func evalIndexExpression(left object.Object, index object.Object) object.Object {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.HASH_OBJ:
+		return evalHashIndexExpression(left, index)
+	default:
+		return newError("index operator not supported: %s[%s]", left.Type(), index.Type())
+	}
+}
+
+func evalArrayIndexExpression(array object.Object, index object.Object) object.Object {
+	elements := array.(*object.Array).Elements
+
+	idx, ok := index.(*object.Integer)
+	if !ok || idx.Value < 0 || idx.Value >= int64(len(elements)) {
+		return newError("index out of range: %s (length %d)", index.Inspect(), len(elements))
+	}
+
+	return elements[idx.Value]
+}
+
+func evalHashIndexExpression(hash object.Object, index object.Object) object.Object {
+	key, ok := index.(object.Hashable)
+	if !ok {
+		return newError("unusable as hash key: %s", index.Type())
+	}
+
+	value, ok := hash.(*object.Hash).Get(key)
+	if !ok {
+		return NULL
+	}
+
+	return value
+}
+
+func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
+	result := make([]object.Object, 0, len(exps))
+
+	for _, exp := range exps {
+		evaluted := evalNode(exp, env)
+		if isError(evaluted) {
+			return []object.Object{evaluted}
+		}
+		result = append(result, evaluted)
+	}
+
+	return result
+}
+
+func evalCallExpression(node *ast.CallExpression, env *object.Environment, tail bool) object.Object {
+	if err := env.Context().Err(); err != nil {
+		return newError("evaluation aborted: %s", err)
+	}
+
+	function := evalNode(node.Function, env)
+	if isError(function) {
+		return function
+	}
+
+	args := evalExpressions(node.Arguments, env)
+	if len(args) == 1 && isError(args[0]) {
+		return args[0]
+	}
+
+	args, _, errObj := object.SpreadArguments(args)
+	if errObj != nil {
+		return errObj
+	}
+
+	if tail {
+		return &tailCall{fn: function, args: args, node: node}
+	}
+
+	return applyFunction(function, args, node)
+}
+
+// 末尾位置の関数呼び出し。呼び出し元の関数から戻った後に実行することで
+// 再帰呼び出しによってGoのスタックが伸びないようにする。
+type tailCall struct {
+	fn   object.Object
+	args []object.Object
+	node *ast.CallExpression
+}
+
+func (tc *tailCall) Type() object.ObjectType { return "TAIL_CALL" }
+func (tc *tailCall) Inspect() string         { return tc.node.String() }
+
+// 末尾呼び出しがなくなるまで関数の呼び出しを繰り返す。
+// nodeは呼び出し元の式で、エラーの位置とスタックトレースに使用する。
+func applyFunction(fn object.Object, args []object.Object, node *ast.CallExpression) object.Object {
+	defer func() {
+		if r := recover(); r != nil {
+			panic(addStackFrame(r, node))
+		}
+	}()
+
+	for {
+		res := callFunction(fn, args)
+
+		if err, ok := res.(*object.Error); ok && !err.Pos.IsValid() {
+			err.Pos = node.Pos()
+		}
+
+		tc, ok := res.(*tailCall)
+		if !ok {
+			return res
+		}
+
+		fn, args, node = tc.fn, tc.args, tc.node
+	}
+}
+
+// 評価中に発生したGoのパニック。関数の境界を通過するたびに呼び出し元を記録する。
+type evalPanic struct {
+	value interface{}
+	stack []object.StackFrame
+}
+
+func addStackFrame(r interface{}, node *ast.CallExpression) *evalPanic {
+	p, ok := r.(*evalPanic)
+	if !ok {
+		p = &evalPanic{value: r}
+	}
+
+	name := node.Function.String()
+	if _, ok := node.Function.(*ast.FunctionLiteral); ok {
+		name = "fn"
+	}
+	p.stack = append(p.stack, object.StackFrame{Function: name, Pos: node.Pos()})
+
+	return p
+}
+
+// パニックを最も内側の関数呼び出しの位置のエラーに変換する
+func panicToError(r interface{}, node ast.Node) *object.Error {
+	p, ok := r.(*evalPanic)
+	if !ok {
+		p = &evalPanic{value: r}
+	}
+
+	err := newError("internal error: %v", p.value)
+	err.Stack = p.stack
+
+	if len(p.stack) > 0 {
+		err.Pos = p.stack[0].Pos
+	} else {
+		err.Pos = node.Pos()
+	}
+
+	return err
+}
+
+// 関数本体の末尾呼び出しは実行せずにtailCallとして返す
+func callFunction(fn object.Object, args []object.Object) object.Object {
+	if builtin, ok := fn.(*object.Builtin); ok {
+		return builtin.Call(invoke, args...)
+	}
+
+	function, ok := fn.(*object.Function)
+	if !ok {
+		return newError("not a function: %s", fn.Type())
+	}
+
+	args, errObj := object.BindArguments(function.Literal, args)
+	if errObj != nil {
+		return errObj
+	}
+
+	env, errObj := extendFunctionEnv(function, args)
+	if errObj != nil {
+		return errObj
+	}
+
+	// 本体の評価はGoのスタックを消費するため、入れ子になった呼び出しの深さを制限する
+	defer env.AddCallDepth(-1)
+	if env.AddCallDepth(1) > MAX_CALL_DEPTH {
+		return newError("stack overflow")
+	}
+	evaluted := evalFunctionBody(function.Body, env)
+
+	return unwrapReturnValue(evaluted)
+}
+
+// 組み込み関数から関数を呼び出す（object.Caller）。末尾呼び出しは呼び出し終えるまで繰り返す。
+func invoke(fn object.Object, args ...object.Object) object.Object {
+	for {
+		res := callFunction(fn, args)
+
+		tc, ok := res.(*tailCall)
+		if !ok {
+			return res
+		}
+
+		fn, args = tc.fn, tc.args
+	}
+}
+
+// 最後の文を末尾位置として評価する
+func evalFunctionBody(block *ast.BlockStatement, env *object.Environment) object.Object {
+	var res object.Object
+
+	for i, s := range block.Statements {
+		if es, ok := s.(*ast.ExpressionStatement); ok && i == len(block.Statements)-1 {
+			return evalTailExpression(es.Expression, env)
+		}
+
+		res = evalNode(s, env)
+
+		if res != nil {
+			if rt := res.Type(); rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+				return res
+			}
+		}
+	}
+
+	return res
+}
+
+// 末尾位置にある関数呼び出しと、if式の末尾位置にある関数呼び出しをtailCallとする
+func evalTailExpression(node ast.Expression, env *object.Environment) object.Object {
+	var res object.Object
+
+	switch node := node.(type) {
+	case *ast.CallExpression:
+		res = evalCallExpression(node, env, true)
+
+	case *ast.IfExpression:
+		condition := evalNode(node.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+
+		if isTruthy(condition) {
+			res = evalFunctionBody(node.Consequence, env)
+		} else if node.Alternative != nil {
+			res = evalFunctionBody(node.Alternative, env)
+		}
+
+		if res == nil {
+			res = NULL
+		}
+
+	default:
+		return evalNode(node, env)
+	}
+
+	if err, ok := res.(*object.Error); ok && !err.Pos.IsValid() {
+		err.Pos = node.Pos()
+	}
+
+	return res
+}
+
+// 渡されなかった引数のデフォルト値は、それより前の引数を定義した環境で評価する
+func extendFunctionEnv(fn *object.Function, args []object.Object) (*object.Environment, *object.Error) {
+	env := object.NewEnclosedEnvironment(fn.Env)
+
+	for i, param := range fn.Literal.Parameters {
+		val := args[i]
+		if val == nil {
+			val = evalNode(fn.Literal.Defaults[i], env)
+			if err, ok := val.(*object.Error); ok {
+				return nil, err
+			}
+		}
+		env.Define(param.Value, val)
+	}
+
+	if fn.Literal.Rest != nil {
+		env.Define(fn.Literal.Rest.Value, args[len(fn.Literal.Parameters)])
+	}
+
+	return env, nil
+}
+
+func unwrapReturnValue(obj object.Object) object.Object {
+	if obj == nil {
+		return NULL
+	}
+
+	if returnValue, ok := obj.(*object.ReturnValue); ok {
+		return returnValue.Value
+	}
+
+	return obj
+}
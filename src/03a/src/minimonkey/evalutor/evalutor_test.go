@@ -7,101 +7,6 @@ import (
 	"testing"
 )
 
-func TestEvalIntegerLiteral(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected int64
-	}{
-		{"5", 5},
-		{"10", 10},
-	}
-
-	for _, tt := range tests {
-		evaluted := testEval(tt.input)
-		testIntegerObject(t, evaluted, tt.expected)
-	}
-}
-
-func TestEvalPrefixExpression(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected int64
-	}{
-		{"-5", -5},
-		{"-10", -10},
-	}
-
-	for _, tt := range tests {
-		evaluted := testEval(tt.input)
-		testIntegerObject(t, evaluted, tt.expected)
-	}
-}
-
-func TestEvalInfixExpression(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected int64
-	}{
-		{"5 + 5 + 5 + 5 - 10", 10},
-		{"2 * 2 * 2 * 2 * 2", 32},
-		{"-50 + 100 + -50", 0},
-		{"5 * 2 + 10", 20},
-		{"5 + 2 * 10", 25},
-		{"20 + 2 * -10", 0},
-		{"50 / 2 * 2 + 10", 60},
-		{"2 * (5 + 10)", 30},
-		{"3 * 3 * 3 + 10", 37},
-		{"3 * (3 * 3) + 10", 37},
-		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
-	}
-
-	for _, tt := range tests {
-		evaluted := testEval(tt.input)
-		testIntegerObject(t, evaluted, tt.expected)
-	}
-}
-
-func TestEvalLetStatement(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected int64
-	}{
-		{"let a = 5; a;", 5},
-		{"let a = 5 * 5; a;", 25},
-		{"let a = 5; let b = a; b;", 5},
-		{"let a = 5; let b = a; let c = a + b + 5; c;", 15},
-	}
-
-	for _, tt := range tests {
-		evaluted := testEval(tt.input)
-		testIntegerObject(t, evaluted, tt.expected)
-	}
-}
-
-func TestErrorHandling(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected string
-	}{
-		{"foobar", "identifier not found: foobar"},
-		{"let v = fn(){}(); v + 1;", "unknown operator NULL + INTEGER"},
-	}
-
-	for _, tt := range tests {
-		evaluted := testEval(tt.input)
-
-		errObj, ok := evaluted.(*object.Error)
-		if !ok {
-			t.Errorf("object is not Error got %T (%+v)", evaluted, evaluted)
-			continue
-		}
-
-		if errObj.Message != tt.expected {
-			t.Errorf("errObj.Message got %q, expected %q", errObj.Message, tt.expected)
-		}
-	}
-}
-
 func testEval(input string) object.Object {
 	l := lexer.New(input)
 	p := parser.New(l)
@@ -124,54 +29,6 @@ func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
 	return true
 }
 
-func TestEvalEmptyStatement(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected interface{}
-	}{
-		{";", nil},
-		{"; 10;", 10},
-		{"10; ;", nil},
-	}
-
-	for _, tt := range tests {
-		evaluted := testEval(tt.input)
-
-		if tt.expected == nil {
-			if evaluted != NULL {
-				t.Error("evaluted is not a NULL")
-			}
-		} else {
-			testIntegerObject(t, evaluted, int64(tt.expected.(int)))
-		}
-	}
-}
-
-func TestEvalReturnStatement(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected interface{}
-	}{
-		{"return 10; 9;", 10},
-		{"return 2 * 5; 9;", 10},
-		{"9; return 2 * 5; 9;", 10},
-		{"return;", nil},
-		{"return; return 10", nil},
-	}
-
-	for _, tt := range tests {
-		evaluted := testEval(tt.input)
-
-		if tt.expected == nil {
-			if evaluted != NULL {
-				t.Error("evaluted is not a NULL")
-			}
-		} else {
-			testIntegerObject(t, evaluted, int64(tt.expected.(int)))
-		}
-	}
-}
-
 func TestEvalFunctionLiteral(t *testing.T) {
 	tests := []struct {
 		input  string
@@ -212,33 +69,179 @@ func TestEvalFunctionLiteral(t *testing.T) {
 	}
 }
 
-func TestEvalCallExpression(t *testing.T) {
+func TestEvalHashLiteral(t *testing.T) {
+	input := `let two = "two";
+{
+	"one": 10 - 9,
+	two: 1 + 1,
+	"thr" + "ee": 6 / 2,
+	4: 4,
+	true: 5,
+	false: 6,
+}`
+
+	evaluted := testEval(input)
+	hash, ok := evaluted.(*object.Hash)
+	if !ok {
+		t.Fatalf("object is not Hash got %T (%+v)", evaluted, evaluted)
+	}
+
+	expected := []struct {
+		key   object.Hashable
+		value int64
+	}{
+		{&object.String{Value: "one"}, 1},
+		{&object.String{Value: "two"}, 2},
+		{&object.String{Value: "three"}, 3},
+		{&object.Integer{Value: 4}, 4},
+		{TRUE, 5},
+		{FALSE, 6},
+	}
+
+	if hash.Len() != len(expected) {
+		t.Fatalf("hash.Len() got %d, expected %d", hash.Len(), len(expected))
+	}
+
+	for i, pair := range hash.Pairs() {
+		if pair.Key.Inspect() != expected[i].key.Inspect() {
+			t.Errorf("hash.Pairs()[%d].Key got %s, expected %s", i, pair.Key.Inspect(), expected[i].key.Inspect())
+		}
+
+		value, ok := hash.Get(expected[i].key)
+		if !ok {
+			t.Errorf("no pair for given key %s", expected[i].key.Inspect())
+			continue
+		}
+		testIntegerObject(t, value, expected[i].value)
+	}
+
+	if hash.Inspect() != `{"one": 1, "two": 2, "three": 3, 4: 4, true: 5, false: 6}` {
+		t.Errorf("hash.Inspect() got %q", hash.Inspect())
+	}
+}
+
+func TestEvalTailCall(t *testing.T) {
 	tests := []struct {
 		input    string
-		expected interface{}
+		expected int64
 	}{
-		{"let identity = fn(x) { x }; identity(5);", 5},
-		{"let identity = fn(x) { return x }; identity(5);", 5},
-		{"let double = fn(x) { x * 2 }; double(5);", 10},
-		{"let add = fn(x, y) { x + y }; add(5, 5);", 10},
-		{"let add = fn(x, y) { x + y }; add(5 + 5, add(5, 5));", 20},
-		{"let x = 5; let y = fn(x) { x }(10); x;", 5},
-		{"fn(x) { x }(5)", 5},
-		{"fn() {}()", nil},
-		{"fn() { return }()", nil},
-		{"let callTwoTimes = fn(x, func) { func(func(x)) }; callTwoTimes(3, fn(x) { x + 1 });", 5}, // high-oder function
-		{"let newAdder = fn(x) { fn(n) { x + n } }; let addTwo = newAdder(2); addTwo(2);", 4},      // closure
+		{"let loop = fn(n, acc) { if (n == 0) { acc } else { loop(n - 1, acc + n) } }; loop(300000, 0)", 45000150000},
+		{"let loop = fn(n, acc) { if (n == 0) { return acc }; return loop(n - 1, acc + 1) }; loop(300000, 0)", 300000},
+		{"let loop = fn(n) { if (n > 0) { return loop(n - 1) }; 42 }; loop(300000)", 42},
+		{"let even = fn(n) { if (n == 0) { 1 } else { odd(n - 1) } }; let odd = fn(n) { if (n == 0) { 0 } else { even(n - 1) } }; even(300001)", 0},
+		{"let f = fn(n) { n * 2 }; let g = fn(n) { f(n + 1) }; g(1) + g(2)", 10},
+		{"let f = fn(n) { n }; return f(5); 0", 5},
+		{"let f = fn() { len([1, 2]) }; f()", 2},
 	}
 
 	for _, tt := range tests {
 		evaluted := testEval(tt.input)
+		testIntegerObject(t, evaluted, tt.expected)
+	}
+}
 
-		if tt.expected == nil {
-			if evaluted != NULL {
-				t.Error("evaluted is not a NULL")
-			}
-		} else {
-			testIntegerObject(t, evaluted, int64(tt.expected.(int)))
+// int64に収まる結果はIntegerに戻る
+func TestEvalBigIntNormalize(t *testing.T) {
+	evaluted := testEval("9223372036854775807 + 10 - 10")
+	testIntegerObject(t, evaluted, 9223372036854775807)
+}
+
+// Inspectの結果を評価すると同じ値となる
+func TestFloatInspectRoundTrip(t *testing.T) {
+	values := []float64{0, 1, 0.1, 1.0 / 3, 123456.789, 1e-5, 1e-300, 1.7976931348623157e308, 5e-324, 1e20, 1e21}
+
+	for _, v := range values {
+		s := (&object.Float{Value: v}).Inspect()
+
+		evaluted := testEval(s)
+		f, ok := evaluted.(*object.Float)
+		if !ok {
+			t.Errorf("%q: object is not Float got %T (%+v)", s, evaluted, evaluted)
+			continue
 		}
+		if f.Value != v {
+			t.Errorf("%q: got %v, expected %v", s, f.Value, v)
+		}
+	}
+}
+
+// 呼び出しの深さは上限を超えてエラーとなった後も正しく数える
+func TestEvalCallDepth(t *testing.T) {
+	env := object.NewEnvironment()
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let f = fn(n) { if (n == 0) { 0 } else { 1 + f(n - 1) } }; f(100000)", "ERROR: 1:46: stack overflow"},
+		{"f(60000)", "60000"},
+	}
+
+	for _, tt := range tests {
+		evaluted := Eval(parser.New(lexer.New(tt.input)).Parse(), env)
+		if evaluted.Inspect() != tt.expected {
+			t.Errorf("%q: evaluted.Inspect() got %q, expected %q", tt.input, evaluted.Inspect(), tt.expected)
+		}
+	}
+}
+
+// constへの代入と再宣言は評価を始める前に検出する（以前の評価で宣言した名前を含む）
+func TestEvalResolve(t *testing.T) {
+	calls := 0
+	env := object.NewEnvironment()
+	env.Define("record", &object.Builtin{Name: "record", Fn: func(args ...object.Object) object.Object {
+		calls += 1
+		return NULL
+	}})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"record(); const x = 1; x = 2", "ERROR: 1:24: cannot assign to constant: x"},
+		{"const y = 1", "1"},
+		{"record(); if (false) { y += 1 }", "ERROR: 1:24: cannot assign to constant: y"},
+		{"record(); let f = fn() { let y = 2 }; let y = 3", "ERROR: 1:39: cannot redeclare constant: y"},
+	}
+
+	for _, tt := range tests {
+		evaluted := Eval(parser.New(lexer.New(tt.input)).Parse(), env)
+		if evaluted.Inspect() != tt.expected {
+			t.Errorf("%q: evaluted.Inspect() got %q, expected %q", tt.input, evaluted.Inspect(), tt.expected)
+		}
+	}
+
+	if calls != 0 {
+		t.Errorf("record called %d times, expected none", calls)
+	}
+}
+
+func TestEvalRecoverPanic(t *testing.T) {
+	input := `let f = fn() { boom() + 1 };
+let g = fn(x) {
+  f() + x
+};
+g(1)`
+	expected := "ERROR: 1:16: internal error: boom\n    at boom (1:16)\n    at f (3:3)\n    at g (5:1)"
+
+	program := parser.New(lexer.New(input)).Parse()
+	env := object.NewEnvironment()
+	env.Define("boom", &object.Builtin{Name: "boom", Fn: func(args ...object.Object) object.Object {
+		panic("boom")
+	}})
+
+	evaluted := Eval(program, env)
+
+	errObj, ok := evaluted.(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error got %T (%+v)", evaluted, evaluted)
+	}
+	if errObj.Inspect() != expected {
+		t.Errorf("errObj.Inspect() got %q, expected %q", errObj.Inspect(), expected)
+	}
+
+	evaluted = Eval(parser.New(lexer.New("1;\nboom()")).Parse(), env)
+	if evaluted.Inspect() != "ERROR: 2:1: internal error: boom\n    at boom (2:1)" {
+		t.Errorf("evaluted.Inspect() got %q", evaluted.Inspect())
 	}
 }
@@ -0,0 +1,173 @@
+package evalutor
+
+import (
+	"minimonkey/ast"
+	"minimonkey/object"
+)
+
+// 評価を始める前に、constへの代入と同じスコープでの再宣言を検出する。
+// コンパイラと同様に、実行されない分岐の中のものもエラーとする。
+type resolver struct {
+	scope *scope
+	env   *object.Environment // 以前の評価で束縛した名前（最も外側のスコープ）
+}
+
+// 関数またはループの本体のスコープ
+type scope struct {
+	outer *scope
+	names map[string]bool // このスコープで宣言された名前（constであればtrue）
+}
+
+func resolve(program *ast.Program, env *object.Environment) *object.Error {
+	r := &resolver{scope: &scope{names: make(map[string]bool)}, env: env}
+
+	for _, s := range program.Statements {
+		if err := r.resolveNode(s); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *resolver) enterScope() {
+	r.scope = &scope{outer: r.scope, names: make(map[string]bool)}
+}
+
+func (r *resolver) leaveScope() {
+	r.scope = r.scope.outer
+}
+
+// このスコープで宣言済みであればtrueを返す（外側のスコープは含まない）
+func (r *resolver) declared(name string) bool {
+	if _, ok := r.scope.names[name]; ok {
+		return true
+	}
+	return r.scope.outer == nil && r.env.Declared(name)
+}
+
+// 名前が宣言されている最も内側のスコープで、constとして宣言されていればtrueを返す
+func (r *resolver) isConst(name string) bool {
+	for s := r.scope; s != nil; s = s.outer {
+		if constant, ok := s.names[name]; ok {
+			return constant
+		}
+	}
+	return r.env.IsConst(name)
+}
+
+func (r *resolver) resolveNode(node ast.Node) *object.Error {
+	switch node := node.(type) {
+	case *ast.BlockStatement:
+		for _, s := range node.Statements {
+			if err := r.resolveNode(s); err != nil {
+				return err
+			}
+		}
+	case *ast.LetStatement:
+		name := node.Name.Value
+		if r.declared(name) {
+			if r.isConst(name) {
+				return r.errorAt(node, "cannot redeclare constant: %s", name)
+			}
+			if r.env.Strict() {
+				return r.errorAt(node, "identifier already declared: %s", name)
+			}
+		}
+		// 関数の本体からは定義した名前を参照できる
+		r.scope.names[name] = node.IsConst()
+		return r.resolveNode(node.Value)
+	case *ast.AssignStatement:
+		if r.isConst(node.Name.Value) {
+			return r.errorAt(node, "cannot assign to constant: %s", node.Name.Value)
+		}
+		return r.resolveNode(node.Value)
+	case *ast.ExpressionStatement:
+		return r.resolveNode(node.Expression)
+	case *ast.ReturnStatement:
+		if node.ReturnValue != nil {
+			return r.resolveNode(node.ReturnValue)
+		}
+	case *ast.WhileStatement:
+		if err := r.resolveNode(node.Condition); err != nil {
+			return err
+		}
+		r.enterScope()
+		defer r.leaveScope()
+		return r.resolveNode(node.Body)
+	case *ast.ForStatement:
+		if err := r.resolveNode(node.Iterable); err != nil {
+			return err
+		}
+		r.enterScope()
+		defer r.leaveScope()
+		r.scope.names[node.Variable.Value] = false
+		return r.resolveNode(node.Body)
+
+	case *ast.PrefixExpression:
+		return r.resolveNode(node.Right)
+	case *ast.InfixExpression:
+		return r.resolveNodes(node.Left, node.Right)
+	case *ast.IfExpression:
+		if err := r.resolveNode(node.Condition); err != nil {
+			return err
+		}
+		if err := r.resolveNode(node.Consequence); err != nil {
+			return err
+		}
+		if node.Alternative != nil {
+			return r.resolveNode(node.Alternative)
+		}
+	case *ast.FunctionLiteral:
+		r.enterScope()
+		defer r.leaveScope()
+		for i, p := range node.Parameters {
+			r.scope.names[p.Value] = false
+			if d := node.Default(i); d != nil {
+				if err := r.resolveNode(d); err != nil {
+					return err
+				}
+			}
+		}
+		if node.Rest != nil {
+			r.scope.names[node.Rest.Value] = false
+		}
+		return r.resolveNode(node.Body)
+	case *ast.CallExpression:
+		if err := r.resolveNode(node.Function); err != nil {
+			return err
+		}
+		return r.resolveNodes(node.Arguments...)
+	case *ast.SpreadExpression:
+		return r.resolveNode(node.Value)
+	case *ast.KeywordArgument:
+		return r.resolveNode(node.Value)
+	case *ast.ArrayLiteral:
+		return r.resolveNodes(node.Elements...)
+	case *ast.HashLiteral:
+		for _, pair := range node.Pairs {
+			if err := r.resolveNodes(pair.Key, pair.Value); err != nil {
+				return err
+			}
+		}
+	case *ast.IndexExpression:
+		return r.resolveNodes(node.Left, node.Index)
+	}
+
+	return nil
+}
+
+func (r *resolver) resolveNodes(nodes ...ast.Expression) *object.Error {
+	for _, node := range nodes {
+		if err := r.resolveNode(node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *resolver) errorAt(node ast.Node, format string, a ...interface{}) *object.Error {
+	err := newError(format, a...)
+	err.Pos = node.Pos()
+	return err
+}
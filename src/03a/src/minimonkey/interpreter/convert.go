@@ -0,0 +1,128 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+
+	"minimonkey/evalutor"
+	"minimonkey/object"
+)
+
+// Goの値をオブジェクトに変換する。
+// 整数（*big.Intを含む）、浮動小数点数、文字列、真偽値、nil、およびそれらを要素とするスライス、配列、マップに対応する。
+func ToObject(value interface{}) (object.Object, error) {
+	switch v := value.(type) {
+	case nil:
+		return evalutor.NULL, nil
+	case object.Object:
+		return v, nil
+	case *big.Int:
+		return object.NewInteger(new(big.Int).Set(v)), nil
+	}
+
+	rv := reflect.ValueOf(value)
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		if rv.Bool() {
+			return evalutor.TRUE, nil
+		}
+		return evalutor.FALSE, nil
+
+	case reflect.String:
+		return &object.String{Value: rv.String()}, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &object.Integer{Value: rv.Int()}, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return object.NewInteger(new(big.Int).SetUint64(rv.Uint())), nil
+
+	case reflect.Float32, reflect.Float64:
+		// 無限大と非数はリテラルとして表せない
+		if f := rv.Float(); math.IsInf(f, 0) || math.IsNaN(f) {
+			return nil, fmt.Errorf("cannot convert %v to object", f)
+		}
+		return &object.Float{Value: rv.Float()}, nil
+
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return evalutor.NULL, nil
+		}
+
+		elements := make([]object.Object, rv.Len())
+		for i := range elements {
+			e, err := ToObject(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = e
+		}
+		return &object.Array{Elements: elements}, nil
+
+	case reflect.Map:
+		if rv.IsNil() {
+			return evalutor.NULL, nil
+		}
+
+		hash := object.NewHash()
+		iter := rv.MapRange()
+		for iter.Next() {
+			k, err := ToObject(iter.Key().Interface())
+			if err != nil {
+				return nil, err
+			}
+			key, ok := k.(object.Hashable)
+			if !ok {
+				return nil, fmt.Errorf("unusable as hash key: %s", k.Type())
+			}
+
+			v, err := ToObject(iter.Value().Interface())
+			if err != nil {
+				return nil, err
+			}
+
+			hash.Set(key, v)
+		}
+		return hash, nil
+	}
+
+	return nil, fmt.Errorf("cannot convert %T to object", value)
+}
+
+// オブジェクトをGoの値に変換する。
+// INTEGERはint64（int64に収まらない場合は*big.Int）、FLOATはfloat64、STRINGはstring、BOOLEANはbool、NULLはnil、
+// ARRAYは[]interface{}、HASHはmap[interface{}]interface{}となる。
+// それ以外（関数など）はオブジェクトをそのまま返す。
+func FromObject(obj object.Object) interface{} {
+	switch obj := obj.(type) {
+	case nil, *object.Null:
+		return nil
+	case *object.Integer:
+		return obj.Value
+	case *object.BigInt:
+		return new(big.Int).Set(obj.Value)
+	case *object.Float:
+		return obj.Value
+	case *object.String:
+		return obj.Value
+	case *object.Boolean:
+		return obj.Value
+	case *object.Array:
+		values := make([]interface{}, len(obj.Elements))
+		for i, e := range obj.Elements {
+			values[i] = FromObject(e)
+		}
+		return values
+	case *object.Hash:
+		values := make(map[interface{}]interface{}, obj.Len())
+		for _, pair := range obj.Pairs() {
+			values[FromObject(pair.Key)] = FromObject(pair.Value)
+		}
+		return values
+	default:
+		return obj
+	}
+}
@@ -0,0 +1,92 @@
+package interpreter
+
+import (
+	"math"
+	"math/big"
+	"minimonkey/object"
+	"reflect"
+	"testing"
+)
+
+func TestToObject(t *testing.T) {
+	type name string
+
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{nil, "null"},
+		{1, "1"},
+		{int8(-8), "-8"},
+		{uint32(32), "32"},
+		{uint64(1 << 63), "9223372036854775808"},
+		{new(big.Int).Lsh(big.NewInt(1), 70), "1180591620717411303424"},
+		{big.NewInt(7), "7"},
+		{3.14, "3.14"},
+		{float32(0.5), "0.5"},
+		{"a", "a"},
+		{name("b"), "b"},
+		{true, "true"},
+		{[]interface{}{1, "a", false, nil}, `[1, "a", false, null]`},
+		{[2]string{"x", "y"}, `["x", "y"]`},
+		{[][]int{{1}, {2, 3}}, "[[1], [2, 3]]"},
+		{map[string]int{"a": 1}, `{"a": 1}`},
+		{map[int][]string{1: {"a"}}, `{1: ["a"]}`},
+		{&object.Integer{Value: 5}, "5"},
+	}
+
+	for _, tt := range tests {
+		obj, err := ToObject(tt.value)
+		if err != nil {
+			t.Errorf("ToObject(%#v) returned error: %s", tt.value, err)
+			continue
+		}
+		if obj.Inspect() != tt.expected {
+			t.Errorf("ToObject(%#v).Inspect() got %q, expected %q", tt.value, obj.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestToObjectErrors(t *testing.T) {
+	tests := []interface{}{
+		2i,
+		struct{}{},
+		[]interface{}{1, 2i},
+		map[[1]int]int{{1}: 1},
+		map[float64]int{1.5: 1},
+		math.Inf(1),
+		math.NaN(),
+	}
+
+	for _, v := range tests {
+		if _, err := ToObject(v); err == nil {
+			t.Errorf("ToObject(%#v) returned no error", v)
+		}
+	}
+}
+
+func TestFromObject(t *testing.T) {
+	hash := object.NewHash()
+	hash.Set(&object.String{Value: "a"}, &object.Integer{Value: 1})
+
+	tests := []struct {
+		obj      object.Object
+		expected interface{}
+	}{
+		{&object.Null{}, nil},
+		{&object.Integer{Value: 1}, int64(1)},
+		{&object.BigInt{Value: new(big.Int).Lsh(big.NewInt(1), 70)}, new(big.Int).Lsh(big.NewInt(1), 70)},
+		{&object.Float{Value: 2.5}, 2.5},
+		{&object.String{Value: "a"}, "a"},
+		{&object.Boolean{Value: true}, true},
+		{&object.Array{Elements: []object.Object{&object.Integer{Value: 1}, &object.String{Value: "a"}}}, []interface{}{int64(1), "a"}},
+		{hash, map[interface{}]interface{}{"a": int64(1)}},
+	}
+
+	for _, tt := range tests {
+		v := FromObject(tt.obj)
+		if !reflect.DeepEqual(v, tt.expected) {
+			t.Errorf("FromObject(%s) got %#v, expected %#v", tt.obj.Inspect(), v, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+// Goのプログラムに組み込んで使用するインタプリタ
+//
+//	in := interpreter.New()
+//	in.SetGlobal("xs", []int{1, 2, 3})
+//	obj, err := in.Eval("len(xs)")
+package interpreter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"minimonkey/ast"
+	"minimonkey/compiler"
+	"minimonkey/evalutor"
+	"minimonkey/lexer"
+	"minimonkey/object"
+	"minimonkey/parser"
+	"minimonkey/vm"
+)
+
+// 評価の方式
+type Engine string
+
+const (
+	EVAL Engine = "eval" // 構文木をたどって評価する
+	VM   Engine = "vm"   // バイトコードにコンパイルして仮想マシンで実行する
+)
+
+// 評価をまたいで束縛を保持する（並行して使用することはできない）
+type Interpreter struct {
+	engine Engine
+
+	env *object.Environment // EVAL
+
+	symbols   *compiler.SymbolTable // VM
+	constants []object.Object
+	globals   []object.Object
+}
+
+func New() *Interpreter {
+	return NewWithEngine(EVAL)
+}
+
+func NewWithEngine(engine Engine) *Interpreter {
+	if engine == VM {
+		return &Interpreter{
+			engine:    VM,
+			symbols:   compiler.NewSymbolTable(),
+			constants: []object.Object{},
+			globals:   make([]object.Object, vm.GLOBALS_SIZE),
+		}
+	}
+	return &Interpreter{engine: EVAL, env: object.NewEnvironment()}
+}
+
+// 構文解析エラー
+type ParseError struct {
+	Errors []error
+	Source string
+}
+
+// 該当するソースコードの行を含めてエラーを表示用に整形する
+func (e *ParseError) Render() string {
+	return parser.RenderErrors(e.Source, e.Errors)
+}
+
+func (e *ParseError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// 評価時のエラー（object.Error）
+type RuntimeError struct {
+	Object *object.Error
+}
+
+func (e *RuntimeError) Error() string {
+	return e.Object.Error()
+}
+
+func (in *Interpreter) Eval(source string) (object.Object, error) {
+	return in.EvalContext(context.Background(), source)
+}
+
+// ctxがキャンセルされると評価を中断し、ctx.Err()を返す
+func (in *Interpreter) EvalContext(ctx context.Context, source string) (object.Object, error) {
+	return in.EvalFile(ctx, "", source)
+}
+
+// エラーの位置にファイル名を含める
+func (in *Interpreter) EvalFile(ctx context.Context, filename string, source string) (object.Object, error) {
+	l := lexer.NewFile(filename, source)
+	p := parser.New(l)
+
+	program := p.Parse()
+	if len(p.Errors()) != 0 {
+		return nil, &ParseError{Errors: p.Errors(), Source: source}
+	}
+
+	var evaluted object.Object
+	if in.engine == VM {
+		var err error
+		if evaluted, err = in.run(ctx, program); err != nil {
+			return nil, err
+		}
+	} else {
+		in.env.SetContext(ctx)
+		defer in.env.SetContext(nil)
+
+		evaluted = evalutor.Eval(program, in.env)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if errObj, ok := evaluted.(*object.Error); ok {
+		return nil, &RuntimeError{Object: errObj}
+	}
+
+	if evaluted == nil {
+		evaluted = evalutor.NULL
+	}
+
+	return evaluted, nil
+}
+
+// バイトコードにコンパイルして実行する（実行時のエラーはobject.Errorとして返す）
+func (in *Interpreter) run(ctx context.Context, program *ast.Program) (res object.Object, err error) {
+	// コンパイル中のGoのパニックも評価器と同様にエラーとする
+	defer func() {
+		if r := recover(); r != nil {
+			res, err = &object.Error{Message: fmt.Sprintf("internal error: %v", r), Pos: program.Pos()}, nil
+		}
+	}()
+
+	c := compiler.NewWithState(in.symbols, in.constants)
+	if err := c.Compile(program); err != nil {
+		return nil, err
+	}
+
+	bytecode := c.Bytecode()
+	in.constants = bytecode.Constants
+
+	machine := vm.NewWithGlobals(bytecode, in.globals)
+	if err := machine.RunContext(ctx); err != nil {
+		if errObj, ok := err.(*object.Error); ok {
+			return errObj, nil
+		}
+		return nil, err
+	}
+
+	return machine.Result(), nil
+}
+
+// letと同様に、constで宣言された名前とstrictの場合の宣言済みの名前はエラーとする
+func (in *Interpreter) set(name string, obj object.Object) error {
+	if in.engine == VM {
+		if in.symbols.Declared(name) {
+			if sym, ok := in.symbols.Resolve(name); ok && sym.Const {
+				return fmt.Errorf("cannot redeclare constant: %s", name)
+			}
+			if in.symbols.Strict() {
+				return fmt.Errorf("identifier already declared: %s", name)
+			}
+		}
+		in.globals[in.symbols.Define(name).Index] = obj
+		return nil
+	}
+
+	if in.env.Declared(name) {
+		if in.env.IsConst(name) {
+			return fmt.Errorf("cannot redeclare constant: %s", name)
+		}
+		if in.env.Strict() {
+			return fmt.Errorf("identifier already declared: %s", name)
+		}
+	}
+	in.env.Define(name, obj)
+	return nil
+}
+
+func (in *Interpreter) get(name string) (object.Object, bool) {
+	if in.engine == VM {
+		sym, ok := in.symbols.Resolve(name)
+		if !ok || in.globals[sym.Index] == nil {
+			return nil, false
+		}
+		return in.globals[sym.Index], true
+	}
+	return in.env.Get(name)
+}
+
+// trueの場合、同じスコープでletにより宣言済みの名前を再び宣言するとエラーとなる。
+// 既定ではREPLと同様に再宣言を許可する（constの再宣言は常にエラーとなる）。
+func (in *Interpreter) SetStrict(strict bool) {
+	if in.engine == VM {
+		in.symbols.SetStrict(strict)
+		return
+	}
+	in.env.SetStrict(strict)
+}
+
+// 組み込み関数を登録する。同名の組み込み関数や束縛は上書きされる
+// （SetGlobalと同様に、constの名前とstrictの場合の宣言済みの名前はエラーとなる）。
+func (in *Interpreter) RegisterBuiltin(name string, fn object.BuiltinFunction) error {
+	return in.set(name, &object.Builtin{Name: name, Fn: fn})
+}
+
+// Goの値をMiniMonkeyのオブジェクトに変換して束縛する
+func (in *Interpreter) SetGlobal(name string, value interface{}) error {
+	obj, err := ToObject(value)
+	if err != nil {
+		return err
+	}
+	return in.set(name, obj)
+}
+
+// 束縛されている名前を昇順で返す
+func (in *Interpreter) GlobalNames() []string {
+	if in.engine == VM {
+		names := []string{}
+		for _, name := range in.symbols.GlobalNames() {
+			if _, ok := in.get(name); ok {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	return in.env.Names()
+}
+
+// 束縛されたオブジェクトを変換せずに返す
+func (in *Interpreter) GlobalObject(name string) (object.Object, bool) {
+	return in.get(name)
+}
+
+// 束縛されたオブジェクトをGoの値に変換して返す
+func (in *Interpreter) Global(name string) (interface{}, bool) {
+	obj, ok := in.get(name)
+	if !ok {
+		return nil, false
+	}
+	return FromObject(obj), true
+}
+
+// putsの出力先を変更する（組み込み関数の出力先はすべてのインタプリタで共有する）
+func (in *Interpreter) SetOutput(w io.Writer) {
+	evalutor.SetOutput(w)
+}
@@ -0,0 +1,299 @@
+package interpreter
+
+import (
+	"bytes"
+	"context"
+	"minimonkey/object"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	in := New()
+
+	obj, err := in.Eval("let add = fn(x, y) { x + y }; add(1, 2)")
+	if err != nil {
+		t.Fatalf("Eval() returned error: %s", err)
+	}
+	if obj.Inspect() != "3" {
+		t.Errorf("obj.Inspect() got %q, expected %q", obj.Inspect(), "3")
+	}
+
+	// 束縛は評価をまたいで保持される
+	obj, err = in.Eval("add(3, 4)")
+	if err != nil {
+		t.Fatalf("Eval() returned error: %s", err)
+	}
+	if obj.Inspect() != "7" {
+		t.Errorf("obj.Inspect() got %q, expected %q", obj.Inspect(), "7")
+	}
+
+	obj, err = in.Eval(";")
+	if err != nil {
+		t.Fatalf("Eval() returned error: %s", err)
+	}
+	if obj.Type() != object.NULL_OBJ {
+		t.Errorf("obj.Type() got %s, expected %s", obj.Type(), object.NULL_OBJ)
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	in := New()
+
+	_, err := in.Eval("let = 1")
+	if _, ok := err.(*ParseError); !ok {
+		t.Errorf("err is %T (%v), expected *ParseError", err, err)
+	}
+
+	_, err = in.Eval("foobar")
+	rerr, ok := err.(*RuntimeError)
+	if !ok {
+		t.Fatalf("err is %T (%v), expected *RuntimeError", err, err)
+	}
+	if rerr.Error() != "1:1: identifier not found: foobar" {
+		t.Errorf("rerr.Error() got %q, expected %q", rerr.Error(), "1:1: identifier not found: foobar")
+	}
+}
+
+func TestSetStrict(t *testing.T) {
+	for _, engine := range []Engine{EVAL, VM} {
+		in := NewWithEngine(engine)
+
+		// 既定では再宣言できる
+		if _, err := in.Eval("let a = 1; let a = 2"); err != nil {
+			t.Errorf("%s: Eval() returned error: %s", engine, err)
+		}
+
+		in.SetStrict(true)
+
+		tests := []struct {
+			input    string
+			expected string
+		}{
+			{"let a = 3", "1:1: identifier already declared: a"},
+			{"let b = 1; let f = fn() { let b = 2; b }; f()", ""},
+			{"let g = fn(x) { let x = 2 }; g(1)", "1:17: identifier already declared: x"},
+			{"const c = 1", ""},
+			{"c = 2", "1:1: cannot assign to constant: c"},
+			{"a = 4; a", ""},
+		}
+
+		for _, tt := range tests {
+			_, err := in.Eval(tt.input)
+
+			msg := ""
+			if err != nil {
+				msg = err.Error()
+			}
+			if msg != tt.expected {
+				t.Errorf("%s: Eval(%q) returned error %q, expected %q", engine, tt.input, msg, tt.expected)
+			}
+		}
+
+		// Goから束縛する場合も同様にエラーとする
+		if err := in.SetGlobal("d", 1); err != nil {
+			t.Errorf("%s: SetGlobal(%q) returned error: %s", engine, "d", err)
+		}
+		if err := in.SetGlobal("a", 5); err == nil || err.Error() != "identifier already declared: a" {
+			t.Errorf("%s: SetGlobal(%q) returned error %v, expected %q", engine, "a", err, "identifier already declared: a")
+		}
+		if err := in.RegisterBuiltin("c", nil); err == nil || err.Error() != "cannot redeclare constant: c" {
+			t.Errorf("%s: RegisterBuiltin(%q) returned error %v, expected %q", engine, "c", err, "cannot redeclare constant: c")
+		}
+		if v, ok := in.Global("a"); !ok || v != int64(4) {
+			t.Errorf("%s: Global(%q) got (%v, %t), expected (%v, %t)", engine, "a", v, ok, int64(4), true)
+		}
+	}
+}
+
+func TestEvalContext(t *testing.T) {
+	in := New()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	in.RegisterBuiltin("cancel", func(args ...object.Object) object.Object {
+		cancel()
+		return &object.Integer{Value: 0}
+	})
+
+	_, err := in.EvalContext(ctx, "let f = fn(n) { n }; cancel(); f(1)")
+	if err != context.Canceled {
+		t.Errorf("err got %v, expected %v", err, context.Canceled)
+	}
+
+	// キャンセルされたコンテキストは次の評価に影響しない
+	obj, err := in.Eval("f(1)")
+	if err != nil {
+		t.Fatalf("Eval() returned error: %s", err)
+	}
+	if obj.Inspect() != "1" {
+		t.Errorf("obj.Inspect() got %q, expected %q", obj.Inspect(), "1")
+	}
+}
+
+func TestEvalContextLoop(t *testing.T) {
+	for _, engine := range []Engine{EVAL, VM} {
+		in := NewWithEngine(engine)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		in.RegisterBuiltin("cancel", func(args ...object.Object) object.Object {
+			cancel()
+			return &object.Integer{Value: 0}
+		})
+
+		// 関数を呼び出さないループもキャンセルで中断する
+		_, err := in.EvalContext(ctx, "cancel(); while (true) { }")
+		if err != context.Canceled {
+			t.Errorf("%s: err got %v, expected %v", engine, err, context.Canceled)
+		}
+	}
+}
+
+func TestRegisterBuiltin(t *testing.T) {
+	in := New()
+
+	in.RegisterBuiltin("double", func(args ...object.Object) object.Object {
+		n := args[0].(*object.Integer)
+		return &object.Integer{Value: n.Value * 2}
+	})
+
+	obj, err := in.Eval("double(21)")
+	if err != nil {
+		t.Fatalf("Eval() returned error: %s", err)
+	}
+	if obj.Inspect() != "42" {
+		t.Errorf("obj.Inspect() got %q, expected %q", obj.Inspect(), "42")
+	}
+}
+
+// 組み込み関数のパニックは評価時のエラーとなる
+func TestRegisterBuiltinPanic(t *testing.T) {
+	for _, engine := range []Engine{EVAL, VM} {
+		in := NewWithEngine(engine)
+
+		in.RegisterBuiltin("first", func(args ...object.Object) object.Object {
+			return args[0].(*object.Array).Elements[0]
+		})
+
+		_, err := in.Eval("let f = fn(xs) { first(xs) + 1 }; f([])")
+		rerr, ok := err.(*RuntimeError)
+		if !ok {
+			t.Fatalf("%s: err is %T (%v), expected *RuntimeError", engine, err, err)
+		}
+
+		expected := "ERROR: 1:18: internal error: runtime error: index out of range [0] with length 0\n    at first (1:18)\n    at f (1:35)"
+		if rerr.Object.Inspect() != expected {
+			t.Errorf("%s: rerr.Object.Inspect() got %q, expected %q", engine, rerr.Object.Inspect(), expected)
+		}
+	}
+}
+
+func TestSetGlobal(t *testing.T) {
+	in := New()
+
+	globals := map[string]interface{}{
+		"n":     42,
+		"name":  "monkey",
+		"ok":    true,
+		"xs":    []int64{1, 2, 3},
+		"table": map[string]int{"a": 1},
+	}
+	for name, v := range globals {
+		if err := in.SetGlobal(name, v); err != nil {
+			t.Fatalf("SetGlobal(%q) returned error: %s", name, err)
+		}
+	}
+
+	obj, err := in.Eval(`if (ok) { name + ":" + type(n) } else { "" }`)
+	if err != nil {
+		t.Fatalf("Eval() returned error: %s", err)
+	}
+	if obj.Inspect() != "monkey:INTEGER" {
+		t.Errorf("obj.Inspect() got %q, expected %q", obj.Inspect(), "monkey:INTEGER")
+	}
+
+	obj, err = in.Eval(`let total = xs[0] + xs[1] + xs[2] + table["a"]; total`)
+	if err != nil {
+		t.Fatalf("Eval() returned error: %s", err)
+	}
+	if obj.Inspect() != "7" {
+		t.Errorf("obj.Inspect() got %q, expected %q", obj.Inspect(), "7")
+	}
+
+	v, ok := in.Global("total")
+	if !ok || v != int64(7) {
+		t.Errorf("Global(%q) got (%v, %t), expected (%v, %t)", "total", v, ok, int64(7), true)
+	}
+
+	if err := in.SetGlobal("ch", make(chan int)); err == nil {
+		t.Errorf("SetGlobal() with channel returned no error")
+	}
+}
+
+func TestSetOutput(t *testing.T) {
+	for _, engine := range []Engine{EVAL, VM} {
+		in := NewWithEngine(engine)
+
+		var out bytes.Buffer
+		in.SetOutput(&out)
+
+		if _, err := in.Eval(`puts("hello", [1, "a"])`); err != nil {
+			t.Fatalf("%s: Eval() returned error: %s", engine, err)
+		}
+
+		expected := "hello\n[1, \"a\"]\n"
+		if out.String() != expected {
+			t.Errorf("%s: out.String() got %q, expected %q", engine, out.String(), expected)
+		}
+	}
+}
+
+func TestVMEngine(t *testing.T) {
+	in := NewWithEngine(VM)
+
+	in.RegisterBuiltin("double", func(args ...object.Object) object.Object {
+		n := args[0].(*object.Integer)
+		return &object.Integer{Value: n.Value * 2}
+	})
+	if err := in.SetGlobal("xs", []int{1, 2, 3}); err != nil {
+		t.Fatalf("SetGlobal() returned error: %s", err)
+	}
+
+	obj, err := in.Eval("let add = fn(x, y) { x + y }; add(xs[0], double(xs[2]))")
+	if err != nil {
+		t.Fatalf("Eval() returned error: %s", err)
+	}
+	if obj.Inspect() != "7" {
+		t.Errorf("obj.Inspect() got %q, expected %q", obj.Inspect(), "7")
+	}
+
+	// 束縛は評価をまたいで保持される
+	obj, err = in.Eval("let total = add(3, 4); total")
+	if err != nil {
+		t.Fatalf("Eval() returned error: %s", err)
+	}
+	if obj.Inspect() != "7" {
+		t.Errorf("obj.Inspect() got %q, expected %q", obj.Inspect(), "7")
+	}
+	if v, ok := in.Global("total"); !ok || v != int64(7) {
+		t.Errorf("Global(%q) got (%v, %t), expected (%v, %t)", "total", v, ok, int64(7), true)
+	}
+
+	_, err = in.Eval("foobar")
+	rerr, ok := err.(*RuntimeError)
+	if !ok {
+		t.Fatalf("err is %T (%v), expected *RuntimeError", err, err)
+	}
+	if rerr.Error() != "1:1: identifier not found: foobar" {
+		t.Errorf("rerr.Error() got %q, expected %q", rerr.Error(), "1:1: identifier not found: foobar")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	in.RegisterBuiltin("cancel", func(args ...object.Object) object.Object {
+		cancel()
+		return &object.Integer{Value: 0}
+	})
+
+	_, err = in.EvalContext(ctx, "cancel(); add(1, 2)")
+	if err != context.Canceled {
+		t.Errorf("err got %v, expected %v", err, context.Canceled)
+	}
+}
@@ -1,36 +1,103 @@
 package lexer
 
-import "minimonkey/token"
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"minimonkey/token"
+)
 
 type Lexer struct {
 	input        string
-	position     int  // カーソル位置
-	readPosition int  // カーソル位置の次の位置
-	ch           byte // カーソル位置の文字
+	filename     string
+	position     int  // カーソル位置（バイトオフセット）
+	readPosition int  // カーソル位置の次の文字の位置
+	ch           rune // カーソル位置の文字
+	line         int  // カーソル位置の行番号
+	column       int  // カーソル位置の列番号（文字単位）
 	insertSemi   bool
+	comments     []token.Comment
 }
 
+// 閉じられていないブロックコメントのエラーメッセージ
+const UnterminatedComment = "comment not terminated"
+
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	return NewFile("", input)
+}
+
+// トークンの位置にファイル名を含める
+func NewFile(filename string, input string) *Lexer {
+	l := &Lexer{input: input, filename: filename, line: 1}
 	l.readChar() // カーソル位置を初期化する
 	return l
 }
 
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line += 1
+		l.column = 1
+	} else {
+		l.column += 1
+	}
+
+	w := 1
 	if l.readPosition >= len(l.input) {
 		l.ch = 0 // EOF
 	} else {
-		l.ch = l.input[l.readPosition]
+		l.ch, w = utf8.DecodeRuneInString(l.input[l.readPosition:])
 	}
 	l.position = l.readPosition
-	l.readPosition += 1
+	l.readPosition += w
 }
 
-func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
+// カーソル位置
+func (l *Lexer) pos() token.Pos {
+	return token.Pos{
+		Filename: l.filename,
+		Offset:   l.position,
+		Line:     l.line,
+		Column:   l.column,
+	}
+}
 
+func (l *Lexer) NextToken() token.Token {
 	l.skipWhiteSpace()
 
+	for l.ch == '/' && (l.peekChar() == '/' || l.peekChar() == '*') {
+		pos := l.pos()
+
+		newline, ok := l.readComment()
+		if !ok {
+			tok := newIllegal(UnterminatedComment)
+			tok.Pos, tok.End = pos, l.pos()
+			return tok
+		}
+
+		// 改行を含むコメントは改行と同様にセミコロンを挿入する
+		if newline && l.insertSemi {
+			l.insertSemi = false
+			return token.Token{Type: token.SEMICOLON, Literal: ";", Pos: pos, End: l.pos()}
+		}
+
+		l.skipWhiteSpace()
+	}
+
+	pos := l.pos()
+	tok := l.scan()
+	tok.Pos = pos
+	tok.End = l.pos() // 自動挿入されたセミコロンは長さ0となる
+
+	return tok
+}
+
+func (l *Lexer) scan() token.Token {
+	var tok token.Token
+
 	insertSemi := false
 
 	switch ch := l.ch; {
@@ -39,21 +106,63 @@ func (l *Lexer) NextToken() token.Token {
 		tok.Type = token.LookupIdent(tok.Literal)
 		insertSemi = true
 	case isDigit(ch):
-		tok.Type = token.INT
-		tok.Literal = l.readNumber()
+		tok = l.readNumber()
+		insertSemi = true
+	case ch == '.' && isDigit(l.peekChar()):
+		// .5のように整数部を省略した浮動小数点数は受け付けない
+		start := l.position
+		l.readChar()
+		l.readNumber()
+		lit := l.input[start:l.position]
+		tok = newIllegal("float literal %q must have a digit before the decimal point (use 0%s)", lit, lit)
+		insertSemi = true
+	case ch == '"':
+		tok = l.readString()
 		insertSemi = true
 	default:
 		switch ch {
 		case '=':
-			tok = newToken(token.ASSIGN, l.ch)
+			tok = l.switch2(token.ASSIGN, '=', token.EQ)
+		case '!':
+			tok = l.switch2(token.BANG, '=', token.NOT_EQ)
+		case '<':
+			tok = l.switch3(token.LT, '=', token.LT_EQ, '<', token.SHL)
+		case '>':
+			tok = l.switch3(token.GT, '=', token.GT_EQ, '>', token.SHR)
 		case '+':
-			tok = newToken(token.PLUS, l.ch)
+			tok = l.switch2(token.PLUS, '=', token.PLUS_ASSIGN)
 		case '-':
-			tok = newToken(token.MINUS, l.ch)
+			tok = l.switch2(token.MINUS, '=', token.MINUS_ASSIGN)
 		case '*':
-			tok = newToken(token.ASTERISK, l.ch)
+			tok = l.switch2(token.ASTERISK, '=', token.ASTERISK_ASSIGN)
 		case '/':
-			tok = newToken(token.SLASH, l.ch)
+			tok = l.switch2(token.SLASH, '=', token.SLASH_ASSIGN)
+		case '%':
+			tok = newToken(token.PERCENT, l.ch)
+		case '&':
+			tok = newToken(token.AMPERSAND, l.ch)
+		case '|':
+			tok = newToken(token.PIPE, l.ch)
+		case '^':
+			tok = newToken(token.CARET, l.ch)
+		case '~':
+			tok = newToken(token.TILDE, l.ch)
+		case '.':
+			if l.peekChar() != '.' {
+				tok = newIllegal("illegal character %q", l.ch)
+				break
+			}
+			l.readChar()
+			switch l.peekChar() {
+			case '<':
+				l.readChar()
+				tok = token.Token{Type: token.DOTDOT_LT, Literal: "..<"}
+			case '.':
+				l.readChar()
+				tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+			default:
+				tok = token.Token{Type: token.DOTDOT, Literal: ".."}
+			}
 		case '(':
 			tok = newToken(token.LPAREN, l.ch)
 		case ')':
@@ -67,8 +176,15 @@ func (l *Lexer) NextToken() token.Token {
 			}
 			tok = newToken(token.RBRACE, l.ch)
 			insertSemi = true
+		case '[':
+			tok = newToken(token.LBRACKET, l.ch)
+		case ']':
+			tok = newToken(token.RBRACKET, l.ch)
+			insertSemi = true
 		case ',':
 			tok = newToken(token.COMMA, l.ch)
+		case ':':
+			tok = newToken(token.COLON, l.ch)
 		case ';':
 			tok = newToken(token.SEMICOLON, l.ch)
 		case '\n':
@@ -79,7 +195,11 @@ func (l *Lexer) NextToken() token.Token {
 			}
 			tok = token.Token{Literal: "", Type: token.EOF}
 		default:
-			tok = newToken(token.ILLEGAL, l.ch)
+			if l.ch == utf8.RuneError && l.readPosition-l.position == 1 {
+				tok = newIllegal("invalid UTF-8 encoding")
+			} else {
+				tok = newIllegal("illegal character %q", l.ch)
+			}
 		}
 		l.readChar()
 	}
@@ -95,37 +215,272 @@ func (l *Lexer) skipWhiteSpace() {
 	}
 }
 
+// コメントを読み込み、改行を含んでいたかどうかを返す。
+// 行コメントは行末の改行まで読み込む。ブロックコメントが閉じられていない場合はokがfalseとなる。
+func (l *Lexer) readComment() (newline bool, ok bool) {
+	start := l.pos()
+	end := start
+
+	if l.peekChar() == '/' {
+		for l.ch != '\n' && l.ch != 0 {
+			l.readChar()
+		}
+		end = l.pos()
+		newline = l.ch == '\n'
+		if newline {
+			l.readChar()
+		}
+	} else {
+		l.readChar()
+		l.readChar() // "/*"を読み飛ばす
+
+		for !(l.ch == '*' && l.peekChar() == '/') {
+			if l.ch == 0 {
+				return newline, false
+			}
+			if l.ch == '\n' {
+				newline = true
+			}
+			l.readChar()
+		}
+		l.readChar()
+		l.readChar()
+		end = l.pos()
+	}
+
+	l.comments = append(l.comments, token.Comment{
+		Text: l.input[start.Offset:end.Offset],
+		Pos:  start,
+		End:  end,
+	})
+
+	return newline, true
+}
+
+// これまでに読み込んだコメント（フォーマッタがトークンの位置と合わせて復元するために使用する）
+func (l *Lexer) Comments() []token.Comment {
+	return l.comments
+}
+
 func (l *Lexer) readIdentifier() string {
 	start := l.position
-	for isLetter(l.ch) || isDigit(l.ch) { // レター文字と数字の組み合わせを許可する
+	for isLetter(l.ch) || isDigit(l.ch) || l.ch >= utf8.RuneSelf && unicode.IsDigit(l.ch) { // レター文字と数字の組み合わせを許可する
 		l.readChar()
 	}
 	return l.input[start:l.position]
 }
 
-func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+// Unicodeのレター文字（「値」など）を識別子に使用できる
+func isLetter(ch rune) bool {
+	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_' || ch >= utf8.RuneSelf && unicode.IsLetter(ch)
 }
 
-func isDigit(ch byte) bool {
+// 数値リテラルの数字はASCIIのみとする
+func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
-func (l *Lexer) readNumber() string {
+// 文字列リテラルを読み込み、エスケープシーケンスを解釈した値をLiteralとするトークンを返す
+func (l *Lexer) readString() token.Token {
+	var out bytes.Buffer
+	var illegal *token.Token
+
+	for {
+		l.readChar() // 開始の'"'または直前の文字を読み飛ばす
+
+		switch l.ch {
+		case '"':
+			l.readChar()
+			if illegal != nil {
+				return *illegal
+			}
+			return token.Token{Type: token.STRING, Literal: out.String()}
+		case '\n', 0:
+			return newIllegal("unterminated string literal")
+		case '\\':
+			l.readChar()
+			switch l.ch {
+			case 'n':
+				out.WriteByte('\n')
+			case 't':
+				out.WriteByte('\t')
+			case '"':
+				out.WriteByte('"')
+			case '\\':
+				out.WriteByte('\\')
+			case 'u':
+				r, ok := l.readUnicodeEscape()
+				if !ok && illegal == nil {
+					tok := newIllegal("invalid unicode escape sequence in string literal")
+					illegal = &tok
+				}
+				out.WriteRune(r)
+			case '\n', 0:
+				return newIllegal("unterminated string literal")
+			default:
+				if illegal == nil {
+					tok := newIllegal("unknown escape sequence \\%c in string literal", l.ch)
+					illegal = &tok
+				}
+			}
+		default:
+			out.WriteString(l.input[l.position:l.readPosition]) // 不正なUTF-8もそのまま保持する
+		}
+	}
+}
+
+// \u{XXXX}形式のエスケープシーケンスを読み込む（カーソル位置は'u'）
+func (l *Lexer) readUnicodeEscape() (rune, bool) {
+	if l.peekChar() != '{' {
+		return utf8.RuneError, false
+	}
+	l.readChar() // curChar == '{'
+
+	start := l.readPosition
+	for isHexDigit(l.peekChar()) {
+		l.readChar()
+	}
+	digits := l.input[start:l.readPosition]
+
+	if l.peekChar() != '}' {
+		return utf8.RuneError, false
+	}
+	l.readChar() // curChar == '}'
+
+	if len(digits) == 0 || len(digits) > 6 {
+		return utf8.RuneError, false
+	}
+
+	v, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || !utf8.ValidRune(rune(v)) {
+		return utf8.RuneError, false
+	}
+
+	return rune(v), true
+}
+
+func isHexDigit(ch rune) bool {
+	return isDigit(ch) || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
+}
+
+// 整数（123, 0xff, 0o17, 0b101）または浮動小数点数（3.14, 1e-9, 2.5E+3）のリテラルを読み込む。
+// 数字の間には区切りとして'_'を置ける（1_000_000）。
+func (l *Lexer) readNumber() token.Token {
 	start := l.position
-	for isDigit(l.ch) {
+
+	if l.ch == '0' {
+		switch l.peekChar() {
+		case 'x', 'X':
+			return l.readPrefixedInteger(16, "hexadecimal")
+		case 'o', 'O':
+			return l.readPrefixedInteger(8, "octal")
+		case 'b', 'B':
+			return l.readPrefixedInteger(2, "binary")
+		}
+	}
+
+	tokenType := token.TokenType(token.INT)
+
+	ok := l.readDigits(isDigit, false)
+
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		tokenType = token.FLOAT
 		l.readChar()
+		ok = l.readDigits(isDigit, false) && ok
 	}
-	return l.input[start:l.position]
+
+	if l.ch == 'e' || l.ch == 'E' {
+		tokenType = token.FLOAT
+		l.readChar()
+		if l.ch == '+' || l.ch == '-' {
+			l.readChar()
+		}
+		if !isDigit(l.ch) {
+			return newIllegal("exponent has no digits in float literal %q", l.input[start:l.position])
+		}
+		ok = l.readDigits(isDigit, false) && ok
+	}
+
+	lit := l.input[start:l.position]
+	if !ok {
+		return newIllegal("'_' must separate successive digits in number literal %q", lit)
+	}
+
+	return token.Token{Type: tokenType, Literal: lit}
 }
 
-// // カーソル位置の次の文字を取得する
-// func (l *Lexer) peekChar() byte {
-// if l.readPosition >= len(l.input) {
-// return 0
-// }
-// return l.input[l.readPosition]
-// }
+// 0x、0o、0bの接頭辞を持つ整数のリテラルを読み込む（カーソル位置は'0'）
+func (l *Lexer) readPrefixedInteger(base int, name string) token.Token {
+	start := l.position
+	l.readChar()
+	l.readChar() // 接頭辞を読み飛ばす
+
+	digit := isDigit
+	if base == 16 {
+		digit = isHexDigit
+	}
+
+	ok := l.readDigits(digit, true) // 0x_ffのように接頭辞の直後にも'_'を置ける
+	lit := l.input[start:l.position]
+
+	digits := strings.ReplaceAll(lit[2:], "_", "")
+	if digits == "" {
+		return newIllegal("%s literal %q has no digits", name, lit)
+	}
+	for _, ch := range digits {
+		if v, _ := strconv.ParseUint(string(ch), 16, 8); int(v) >= base {
+			return newIllegal("invalid digit %q in %s literal %q", ch, name, lit)
+		}
+	}
+	if !ok {
+		return newIllegal("'_' must separate successive digits in number literal %q", lit)
+	}
+
+	return token.Token{Type: token.INT, Literal: lit}
+}
+
+// 数字の並びを読み込む。'_'が数字の間（leadingがtrueの場合は先頭にも）にのみ現れればtrueを返す。
+func (l *Lexer) readDigits(digit func(rune) bool, leading bool) bool {
+	ok := true
+	prevDigit := leading
+
+	for digit(l.ch) || l.ch == '_' {
+		if l.ch == '_' && !prevDigit {
+			ok = false
+		}
+		prevDigit = l.ch != '_'
+		l.readChar()
+	}
+
+	return ok && prevDigit
+}
+
+// カーソル位置の次の文字を取得する
+func (l *Lexer) peekChar() rune {
+	if l.readPosition >= len(l.input) {
+		return 0
+	}
+	r, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	return r
+}
+
+// 次の文字がnextであれば2文字のトークン（tok2）を、そうでなければ1文字のトークン（tok1）を返す
+func (l *Lexer) switch2(tok1 token.TokenType, next rune, tok2 token.TokenType) token.Token {
+	if l.peekChar() == next {
+		ch := l.ch
+		l.readChar()
+		return token.Token{Type: tok2, Literal: string(ch) + string(l.ch)}
+	}
+	return newToken(tok1, l.ch)
+}
+
+// switch2に加え、次の文字がnext3であれば2文字のトークン（tok3）を返す
+func (l *Lexer) switch3(tok1 token.TokenType, next2 rune, tok2 token.TokenType, next3 rune, tok3 token.TokenType) token.Token {
+	if l.peekChar() == next3 {
+		return l.switch2(tok1, next3, tok3)
+	}
+	return l.switch2(tok1, next2, tok2)
+}
 
 func (l *Lexer) insertSemicolon() *token.Token {
 	if l.insertSemi {
@@ -136,7 +491,15 @@ func (l *Lexer) insertSemicolon() *token.Token {
 	return nil
 }
 
-func newToken(tokenType token.TokenType, ch byte) token.Token {
+// ILLEGALトークンのLiteralにはエラーメッセージを格納する
+func newIllegal(format string, a ...interface{}) token.Token {
+	return token.Token{
+		Type:    token.ILLEGAL,
+		Literal: fmt.Sprintf(format, a...),
+	}
+}
+
+func newToken(tokenType token.TokenType, ch rune) token.Token {
 	return token.Token{
 		Type:    tokenType,
 		Literal: string(ch),
@@ -165,6 +165,515 @@ twice(addTwo, 2)`
 	testNextToken(t, input, tests)
 }
 
+func TestArithmeticToken(t *testing.T) {
+	input := `a + b - c * d / e % f`
+
+	tests := []tokenTest{
+		{token.IDENT, "a"},
+		{token.PLUS, "+"},
+		{token.IDENT, "b"},
+		{token.MINUS, "-"},
+		{token.IDENT, "c"},
+		{token.ASTERISK, "*"},
+		{token.IDENT, "d"},
+		{token.SLASH, "/"},
+		{token.IDENT, "e"},
+		{token.PERCENT, "%"},
+		{token.IDENT, "f"},
+		{token.SEMICOLON, ";"},
+
+		{token.EOF, ""},
+	}
+
+	testNextToken(t, input, tests)
+}
+
+func TestNumberToken(t *testing.T) {
+	input := `3.14 1e-9 2.5E+3 10e2 0.5
+1.x
+.5
+1e+`
+
+	tests := []tokenTest{
+		{token.FLOAT, "3.14"},
+		{token.FLOAT, "1e-9"},
+		{token.FLOAT, "2.5E+3"},
+		{token.FLOAT, "10e2"},
+		{token.FLOAT, "0.5"},
+		{token.SEMICOLON, ";"},
+
+		{token.INT, "1"},
+		{token.ILLEGAL, "illegal character '.'"},
+		{token.IDENT, "x"},
+		{token.SEMICOLON, ";"},
+
+		{token.ILLEGAL, `float literal ".5" must have a digit before the decimal point (use 0.5)`},
+		{token.SEMICOLON, ";"},
+
+		{token.ILLEGAL, `exponent has no digits in float literal "1e+"`},
+		{token.SEMICOLON, ";"},
+
+		{token.EOF, ""},
+	}
+
+	testNextToken(t, input, tests)
+}
+
+func TestPrefixedNumberToken(t *testing.T) {
+	input := `0xff 0XAB_cd 0o17 0b1010 0x_1 1_000_000 1_000.5e1_0
+0x 0b102 0o8 1__0 1_ 0b1_ 1_.5
+0xfg`
+
+	tests := []tokenTest{
+		{token.INT, "0xff"},
+		{token.INT, "0XAB_cd"},
+		{token.INT, "0o17"},
+		{token.INT, "0b1010"},
+		{token.INT, "0x_1"},
+		{token.INT, "1_000_000"},
+		{token.FLOAT, "1_000.5e1_0"},
+		{token.SEMICOLON, ";"},
+
+		{token.ILLEGAL, `hexadecimal literal "0x" has no digits`},
+		{token.ILLEGAL, `invalid digit '2' in binary literal "0b102"`},
+		{token.ILLEGAL, `invalid digit '8' in octal literal "0o8"`},
+		{token.ILLEGAL, `'_' must separate successive digits in number literal "1__0"`},
+		{token.ILLEGAL, `'_' must separate successive digits in number literal "1_"`},
+		{token.ILLEGAL, `'_' must separate successive digits in number literal "0b1_"`},
+		{token.ILLEGAL, `'_' must separate successive digits in number literal "1_.5"`},
+		{token.SEMICOLON, ";"},
+
+		{token.INT, "0xf"},
+		{token.IDENT, "g"},
+		{token.SEMICOLON, ";"},
+
+		{token.EOF, ""},
+	}
+
+	testNextToken(t, input, tests)
+}
+
+func TestBitwiseToken(t *testing.T) {
+	input := `a & b | c ^ ~d
+x << 2 >> 1
+a <<= b >= c`
+
+	tests := []tokenTest{
+		{token.IDENT, "a"},
+		{token.AMPERSAND, "&"},
+		{token.IDENT, "b"},
+		{token.PIPE, "|"},
+		{token.IDENT, "c"},
+		{token.CARET, "^"},
+		{token.TILDE, "~"},
+		{token.IDENT, "d"},
+		{token.SEMICOLON, ";"},
+
+		{token.IDENT, "x"},
+		{token.SHL, "<<"},
+		{token.INT, "2"},
+		{token.SHR, ">>"},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+
+		{token.IDENT, "a"},
+		{token.SHL, "<<"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "b"},
+		{token.GT_EQ, ">="},
+		{token.IDENT, "c"},
+		{token.SEMICOLON, ";"},
+
+		{token.EOF, ""},
+	}
+
+	testNextToken(t, input, tests)
+}
+
+func TestAssignToken(t *testing.T) {
+	input := `x = 1
+x += 1 -= 2 *= 3 /= 4`
+
+	tests := []tokenTest{
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+
+		{token.IDENT, "x"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.INT, "1"},
+		{token.MINUS_ASSIGN, "-="},
+		{token.INT, "2"},
+		{token.ASTERISK_ASSIGN, "*="},
+		{token.INT, "3"},
+		{token.SLASH_ASSIGN, "/="},
+		{token.INT, "4"},
+		{token.SEMICOLON, ";"},
+
+		{token.EOF, ""},
+	}
+
+	testNextToken(t, input, tests)
+}
+
+func TestRangeToken(t *testing.T) {
+	input := `0..10 0..<n
+a.b
+f(...xs)`
+
+	tests := []tokenTest{
+		{token.INT, "0"},
+		{token.DOTDOT, ".."},
+		{token.INT, "10"},
+		{token.INT, "0"},
+		{token.DOTDOT_LT, "..<"},
+		{token.IDENT, "n"},
+		{token.SEMICOLON, ";"},
+
+		{token.IDENT, "a"},
+		{token.ILLEGAL, "illegal character '.'"},
+		{token.IDENT, "b"},
+		{token.SEMICOLON, ";"},
+
+		{token.IDENT, "f"},
+		{token.LPAREN, "("},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "xs"},
+		{token.RPAREN, ")"},
+		{token.SEMICOLON, ";"},
+
+		{token.EOF, ""},
+	}
+
+	testNextToken(t, input, tests)
+}
+
+func TestComparisonToken(t *testing.T) {
+	input := `!true != false
+1 == 1
+a < b > c <= d >= e
+if (x) { 1 } else { 2 }`
+
+	tests := []tokenTest{
+		{token.BANG, "!"},
+		{token.TRUE, "true"},
+		{token.NOT_EQ, "!="},
+		{token.FALSE, "false"},
+		{token.SEMICOLON, ";"},
+
+		{token.INT, "1"},
+		{token.EQ, "=="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+
+		{token.IDENT, "a"},
+		{token.LT, "<"},
+		{token.IDENT, "b"},
+		{token.GT, ">"},
+		{token.IDENT, "c"},
+		{token.LT_EQ, "<="},
+		{token.IDENT, "d"},
+		{token.GT_EQ, ">="},
+		{token.IDENT, "e"},
+		{token.SEMICOLON, ";"},
+
+		{token.IF, "if"},
+		{token.LPAREN, "("},
+		{token.IDENT, "x"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.RBRACE, "}"},
+		{token.ELSE, "else"},
+		{token.LBRACE, "{"},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.RBRACE, "}"},
+		{token.SEMICOLON, ";"},
+
+		{token.EOF, ""},
+	}
+
+	testNextToken(t, input, tests)
+}
+
+func TestStringToken(t *testing.T) {
+	input := `"foobar"
+"foo bar" + ""
+"a\nb\tc\"d\\e"
+"\u{3042}\u{1F600}"
+"unterminated
+"bad \q escape"
+"bad \u{110000} escape"
+"\u3042"
+"eof`
+
+	tests := []tokenTest{
+		{token.STRING, "foobar"},
+		{token.SEMICOLON, ";"},
+
+		{token.STRING, "foo bar"},
+		{token.PLUS, "+"},
+		{token.STRING, ""},
+		{token.SEMICOLON, ";"},
+
+		{token.STRING, "a\nb\tc\"d\\e"},
+		{token.SEMICOLON, ";"},
+
+		{token.STRING, "あ😀"},
+		{token.SEMICOLON, ";"},
+
+		{token.ILLEGAL, "unterminated string literal"},
+		{token.SEMICOLON, ";"},
+
+		{token.ILLEGAL, "unknown escape sequence \\q in string literal"},
+		{token.SEMICOLON, ";"},
+
+		{token.ILLEGAL, "invalid unicode escape sequence in string literal"},
+		{token.SEMICOLON, ";"},
+
+		{token.ILLEGAL, "invalid unicode escape sequence in string literal"},
+		{token.SEMICOLON, ";"},
+
+		{token.ILLEGAL, "unterminated string literal"},
+		{token.SEMICOLON, ";"},
+
+		{token.EOF, ""},
+	}
+
+	testNextToken(t, input, tests)
+}
+
+func TestCollectionToken(t *testing.T) {
+	input := `[1, 2][0]
+{"a": 1}`
+
+	tests := []tokenTest{
+		{token.LBRACKET, "["},
+		{token.INT, "1"},
+		{token.COMMA, ","},
+		{token.INT, "2"},
+		{token.RBRACKET, "]"},
+		{token.LBRACKET, "["},
+		{token.INT, "0"},
+		{token.RBRACKET, "]"},
+		{token.SEMICOLON, ";"},
+
+		{token.LBRACE, "{"},
+		{token.STRING, "a"},
+		{token.COLON, ":"},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.RBRACE, "}"},
+		{token.SEMICOLON, ";"},
+
+		{token.EOF, ""},
+	}
+
+	testNextToken(t, input, tests)
+}
+
+func TestCommentToken(t *testing.T) {
+	input := `// header
+let x = f(1) // call
+let y /* inline */ = 2 /* spans
+lines */ y
+/* before */ x / y
+[1, /* a */
+ 2]
+x // end`
+
+	tests := []tokenTest{
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "f"},
+		{token.LPAREN, "("},
+		{token.INT, "1"},
+		{token.RPAREN, ")"},
+		{token.SEMICOLON, ";"},
+
+		{token.LET, "let"},
+		{token.IDENT, "y"},
+		{token.ASSIGN, "="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+
+		{token.IDENT, "y"},
+		{token.SEMICOLON, ";"},
+
+		{token.IDENT, "x"},
+		{token.SLASH, "/"},
+		{token.IDENT, "y"},
+		{token.SEMICOLON, ";"},
+
+		{token.LBRACKET, "["},
+		{token.INT, "1"},
+		{token.COMMA, ","},
+		{token.INT, "2"},
+		{token.RBRACKET, "]"},
+		{token.SEMICOLON, ";"},
+
+		{token.IDENT, "x"},
+		{token.SEMICOLON, ";"},
+
+		{token.EOF, ""},
+	}
+
+	testNextToken(t, input, tests)
+
+	testNextToken(t, "1 /* open", []tokenTest{
+		{token.INT, "1"},
+		{token.ILLEGAL, UnterminatedComment},
+	})
+}
+
+func TestComments(t *testing.T) {
+	input := "// a\nx /* b */ + y // c\n/* d\n*/"
+
+	expected := []struct {
+		text string
+		pos  string
+		end  string
+	}{
+		{"// a", "1:1", "1:5"},
+		{"/* b */", "2:3", "2:10"},
+		{"// c", "2:15", "2:19"},
+		{"/* d\n*/", "3:1", "4:3"},
+	}
+
+	l := New(input)
+	var semi token.Token
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		if tok.Type == token.SEMICOLON && semi.Type == "" {
+			semi = tok
+		}
+	}
+
+	// 行末のコメントで挿入されたセミコロンはコメントと改行を含む
+	if semi.Pos.String() != "2:15" || semi.End.String() != "3:1" {
+		t.Errorf("semicolon got %s-%s, expected 2:15-3:1", semi.Pos, semi.End)
+	}
+
+	comments := l.Comments()
+	if len(comments) != len(expected) {
+		t.Fatalf("len(comments) got %d, expected %d", len(comments), len(expected))
+	}
+
+	for i, tt := range expected {
+		c := comments[i]
+		if c.Text != tt.text || c.Pos.String() != tt.pos || c.End.String() != tt.end {
+			t.Errorf("comments[%d] got %q %s-%s, expected %q %s-%s", i, c.Text, c.Pos, c.End, tt.text, tt.pos, tt.end)
+		}
+	}
+}
+
+func TestUnicodeToken(t *testing.T) {
+	input := `let 値段 = "日本語"
+x１ + café_2
+　
+"\xff"` + "\xff"
+
+	tests := []tokenTest{
+		{token.LET, "let"},
+		{token.IDENT, "値段"},
+		{token.ASSIGN, "="},
+		{token.STRING, "日本語"},
+		{token.SEMICOLON, ";"},
+
+		{token.IDENT, "x１"},
+		{token.PLUS, "+"},
+		{token.IDENT, "café_2"},
+		{token.SEMICOLON, ";"},
+
+		{token.ILLEGAL, `illegal character '\u3000'`},
+
+		{token.ILLEGAL, `unknown escape sequence \x in string literal`},
+		{token.ILLEGAL, "invalid UTF-8 encoding"},
+
+		{token.EOF, ""},
+	}
+
+	testNextToken(t, input, tests)
+}
+
+// 列番号は文字単位、オフセットはバイト単位とする
+func TestUnicodeTokenPosition(t *testing.T) {
+	input := "let 値段 = 1\n\"円\" + 値段"
+
+	tests := []struct {
+		expectedType token.TokenType
+		pos          string
+		offset       int
+	}{
+		{token.LET, "1:1", 0},
+		{token.IDENT, "1:5", 4},
+		{token.ASSIGN, "1:8", 11},
+		{token.INT, "1:10", 13},
+		{token.SEMICOLON, "1:11", 14},
+		{token.STRING, "2:1", 15},
+		{token.PLUS, "2:5", 21},
+		{token.IDENT, "2:7", 23},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] wrong Type. got=%s, expected=%s", i, tok.Type, tt.expectedType)
+		}
+		if tok.Pos.String() != tt.pos {
+			t.Errorf("tests[%d] wrong Pos. got=%s, expected=%s", i, tok.Pos, tt.pos)
+		}
+		if tok.Pos.Offset != tt.offset {
+			t.Errorf("tests[%d] wrong Offset. got=%d, expected=%d", i, tok.Pos.Offset, tt.offset)
+		}
+	}
+}
+
+func TestTokenPosition(t *testing.T) {
+	input := "let x = 10\n  \"ab\" + x"
+
+	tests := []struct {
+		expectedType token.TokenType
+		pos          string
+		end          string
+		offset       int
+	}{
+		{token.LET, "f.mm:1:1", "f.mm:1:4", 0},
+		{token.IDENT, "f.mm:1:5", "f.mm:1:6", 4},
+		{token.ASSIGN, "f.mm:1:7", "f.mm:1:8", 6},
+		{token.INT, "f.mm:1:9", "f.mm:1:11", 8},
+		{token.SEMICOLON, "f.mm:1:11", "f.mm:2:1", 10},
+		{token.STRING, "f.mm:2:3", "f.mm:2:7", 13},
+		{token.PLUS, "f.mm:2:8", "f.mm:2:9", 18},
+		{token.IDENT, "f.mm:2:10", "f.mm:2:11", 20},
+		{token.SEMICOLON, "f.mm:2:11", "f.mm:2:11", 21},
+		{token.EOF, "f.mm:2:11", "f.mm:2:12", 21},
+	}
+
+	l := NewFile("f.mm", input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] wrong Type. got=%s, expected=%s", i, tok.Type, tt.expectedType)
+		}
+		if tok.Pos.String() != tt.pos {
+			t.Errorf("tests[%d] wrong Pos. got=%s, expected=%s", i, tok.Pos, tt.pos)
+		}
+		if tok.End.String() != tt.end {
+			t.Errorf("tests[%d] wrong End. got=%s, expected=%s", i, tok.End, tt.end)
+		}
+		if tok.Pos.Offset != tt.offset {
+			t.Errorf("tests[%d] wrong Offset. got=%d, expected=%d", i, tok.Pos.Offset, tt.offset)
+		}
+	}
+}
+
 func testNextToken(t *testing.T, input string, tests []tokenTest) {
 	l := New(input)
 
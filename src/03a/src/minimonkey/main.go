@@ -1,12 +1,141 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"minimonkey/interpreter"
+	"minimonkey/object"
 	"minimonkey/repl"
 	"os"
 )
 
+const usage = `Usage:
+  minimonkey                       start the REPL (runs stdin as a script when piped)
+  minimonkey run <file> [args...]  run a script file
+  minimonkey -e <source> [args...] evaluate source and print the result
+
+Options:
+  -engine eval|vm                  evaluate scripts by walking the syntax tree (eval, default)
+                                   or by compiling them to bytecode (vm)
+`
+
 func main() {
-	fmt.Println("This is the MiniMonkey programming language!\n")
-	repl.Start(os.Stdin, os.Stdout)
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// 終了コード: 0 正常終了、1 構文解析エラーまたは評価時のエラー、2 引数の誤り
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("minimonkey", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { fmt.Fprint(stderr, usage) }
+	source := flags.String("e", "", "evaluate `source` and print the result")
+	engine := flags.String("engine", string(interpreter.EVAL), "evaluation `engine` for scripts (eval or vm)")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	switch interpreter.Engine(*engine) {
+	case interpreter.EVAL, interpreter.VM:
+	default:
+		fmt.Fprintf(stderr, "unknown engine %q\n", *engine)
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	in := interpreter.NewWithEngine(interpreter.Engine(*engine))
+
+	isSet := false
+	flags.Visit(func(f *flag.Flag) { isSet = isSet || f.Name == "e" })
+
+	switch {
+	case isSet:
+		return runScript(in, "", *source, flags.Args(), true, stdout, stderr)
+
+	case flags.NArg() > 0 && flags.Arg(0) == "run":
+		if flags.NArg() < 2 {
+			fmt.Fprint(stderr, usage)
+			return 2
+		}
+		filename := flags.Arg(1)
+		src, err := ioutil.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		return runScript(in, filename, string(src), flags.Args()[2:], false, stdout, stderr)
+
+	case flags.NArg() > 0:
+		fmt.Fprintf(stderr, "unknown command %q\n", flags.Arg(0))
+		fmt.Fprint(stderr, usage)
+		return 2
+
+	case isTerminal(stdin):
+		fmt.Fprintln(stdout, "This is the MiniMonkey programming language!")
+		fmt.Fprintln(stdout)
+		startREPL(stdin, stdout, interpreter.Engine(*engine))
+		return 0
+
+	default:
+		src, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		return runScript(in, "<stdin>", string(src), nil, false, stdout, stderr)
+	}
+}
+
+// スクリプトでは同じスコープでの再宣言をエラーとする
+func runScript(in *interpreter.Interpreter, filename string, src string, args []string, printResult bool, stdout io.Writer, stderr io.Writer) int {
+	in.SetOutput(stdout)
+	in.SetStrict(true)
+
+	if args == nil {
+		args = []string{}
+	}
+	if err := in.SetGlobal("args", args); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	obj, err := in.EvalFile(context.Background(), filename, src)
+	switch err := err.(type) {
+	case nil:
+	case *interpreter.ParseError:
+		fmt.Fprint(stderr, err.Render())
+		return 1
+	case *interpreter.RuntimeError:
+		fmt.Fprintln(stderr, err.Object.Inspect())
+		return 1
+	default:
+		fmt.Fprintln(stderr, "ERROR: "+err.Error())
+		return 1
+	}
+
+	if printResult && obj.Type() != object.NULL_OBJ {
+		fmt.Fprintln(stdout, obj.Inspect())
+	}
+
+	return 0
+}
+
+// REPLを起動する（テストでは置き換える）
+var startREPL = repl.Start
+
+// 端末からの入力であればREPLを起動する（テストでは置き換える）
+var isTerminal = func(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
 }
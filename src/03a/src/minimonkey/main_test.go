@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"minimonkey/interpreter"
+	"minimonkey/repl"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "minimonkey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	script := filepath.Join(dir, "script.mm")
+	src := `let greet = fn(name) { "hello, " + name }
+puts(greet(first(args)))
+len(args)
+`
+	if err := ioutil.WriteFile(script, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args   []string
+		stdin  string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{[]string{"run", script, "monkey", "x"}, "", 0, "hello, monkey\n", ""},
+		{[]string{"run", filepath.Join(dir, "missing.mm")}, "", 1, "", "no such file"},
+		{[]string{"run"}, "", 2, "", "Usage:"},
+		{[]string{"-e", "1 + 2"}, "", 0, "3\n", ""},
+		{[]string{"-e", "args", "a", "b"}, "", 0, "[\"a\", \"b\"]\n", ""},
+		{[]string{"-e", "puts(1)"}, "", 0, "1\n", ""},
+		{[]string{"-e", "let = 1"}, "", 1, "", "1:5: expected next token to be IDENT, got =\n    let = 1\n        ^\n"},
+		{[]string{"-e", "1 + true"}, "", 1, "", "ERROR: 1:1: unknown operator INTEGER + BOOLEAN\n"},
+		{[]string{}, "puts(args)\n1 + 1", 0, "[]\n", ""},
+		{[]string{}, "foo", 1, "", "ERROR: <stdin>:1:1: identifier not found: foo\n"},
+		{[]string{"compile"}, "", 2, "", "unknown command \"compile\""},
+		{[]string{"-x"}, "", 2, "", "Usage:"},
+		{[]string{"-engine=vm", "run", script, "monkey"}, "", 0, "hello, monkey\n", ""},
+		{[]string{"-engine=vm", "-e", "let f = fn(n) { if (n < 2) { n } else { f(n - 1) + f(n - 2) } }; f(10)"}, "", 0, "55\n", ""},
+		{[]string{"-engine=vm", "-e", "1 + true"}, "", 1, "", "ERROR: 1:1: unknown operator INTEGER + BOOLEAN\n"},
+		{[]string{"-engine=vm"}, "foo", 1, "", "ERROR: <stdin>:1:1: identifier not found: foo\n"},
+		{[]string{"-e", "let a = 1; let a = 2"}, "", 1, "", "ERROR: 1:12: identifier already declared: a\n"},
+		{[]string{"-engine=vm", "-e", "let a = 1; let a = 2"}, "", 1, "", "ERROR: 1:12: identifier already declared: a\n"},
+		{[]string{"-engine=jit", "-e", "1"}, "", 2, "", "unknown engine \"jit\""},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+
+		code := run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+
+		if code != tt.code {
+			t.Errorf("run(%q) returned %d, expected %d (stderr: %q)", tt.args, code, tt.code, stderr.String())
+		}
+		if stdout.String() != tt.stdout {
+			t.Errorf("run(%q) stdout got %q, expected %q", tt.args, stdout.String(), tt.stdout)
+		}
+		if !strings.Contains(stderr.String(), tt.stderr) {
+			t.Errorf("run(%q) stderr got %q, expected to contain %q", tt.args, stderr.String(), tt.stderr)
+		}
+	}
+}
+
+// REPLも-engineで指定した方式で評価する
+func TestRunREPL(t *testing.T) {
+	defer func(f func(io.Reader) bool) { isTerminal = f }(isTerminal)
+	defer func(f func(io.Reader, io.Writer, interpreter.Engine)) { startREPL = f }(startREPL)
+	isTerminal = func(io.Reader) bool { return true }
+
+	tests := []struct {
+		args   []string
+		engine interpreter.Engine
+	}{
+		{[]string{}, interpreter.EVAL},
+		{[]string{"-engine=eval"}, interpreter.EVAL},
+		{[]string{"-engine=vm"}, interpreter.VM},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		var engine interpreter.Engine
+		startREPL = func(in io.Reader, out io.Writer, e interpreter.Engine) {
+			engine = e
+			repl.Start(in, out, e)
+		}
+
+		code := run(tt.args, strings.NewReader("1 + 2\n"), &stdout, &stderr)
+
+		if code != 0 {
+			t.Errorf("run(%q) returned %d, expected 0 (stderr: %q)", tt.args, code, stderr.String())
+		}
+		if engine != tt.engine {
+			t.Errorf("run(%q) started the REPL with engine %q, expected %q", tt.args, engine, tt.engine)
+		}
+		expected := "This is the MiniMonkey programming language!\n\n>> 3\n>> "
+		if stdout.String() != expected {
+			t.Errorf("run(%q) stdout got %q, expected %q", tt.args, stdout.String(), expected)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package object
+
+import (
+	"fmt"
+	"minimonkey/ast"
+)
+
+// 呼び出し時に展開される引数（f(...xs)）。関数に渡す前に要素に置き換える。
+type Spread struct {
+	Value Object
+}
+
+func (s *Spread) Type() ObjectType { return SPREAD_OBJ }
+func (s *Spread) Inspect() string  { return "..." + s.Value.Inspect() }
+
+// 呼び出し時のキーワード引数（f(b: 3)）。位置による引数の後に並ぶ。
+type Keyword struct {
+	Name  string
+	Value Object
+}
+
+func (k *Keyword) Type() ObjectType { return KEYWORD_OBJ }
+func (k *Keyword) Inspect() string  { return k.Name + ": " + k.Value.Inspect() }
+
+// Spreadを反復した要素に置き換えた引数を返す（Spreadがなければargsをそのまま返す）。
+// 2番目の戻り値はSpreadを展開したかどうか。
+func SpreadArguments(args []Object) ([]Object, bool, *Error) {
+	spread := false
+	for _, arg := range args {
+		if _, ok := arg.(*Spread); ok {
+			spread = true
+			break
+		}
+	}
+	if !spread {
+		return args, false, nil
+	}
+
+	expanded := []Object{}
+	for _, arg := range args {
+		s, ok := arg.(*Spread)
+		if !ok {
+			expanded = append(expanded, arg)
+			continue
+		}
+
+		it, errObj := Iterate(s.Value)
+		if errObj != nil {
+			return nil, false, &Error{Message: "cannot spread " + string(s.Value.Type())}
+		}
+		for {
+			val, ok := it.Next()
+			if !ok {
+				break
+			}
+			if errObj, ok := val.(*Error); ok {
+				return nil, false, errObj
+			}
+			expanded = append(expanded, val)
+		}
+	}
+
+	return expanded, true, nil
+}
+
+// キーワード引数を含む引数を関数の引数の並びに割り当てる。
+// 結果はParametersの順に並び、残りの引数があればその配列を最後に加える。
+// 渡されなかったデフォルト値のある引数はnilとし、呼び出し側がデフォルト値を評価する。
+func BindArguments(fn *ast.FunctionLiteral, args []Object) ([]Object, *Error) {
+	positional := args
+	var keywords []*Keyword
+	for i, arg := range args {
+		if k, ok := arg.(*Keyword); ok {
+			if keywords == nil {
+				positional = args[:i]
+			}
+			keywords = append(keywords, k)
+		}
+	}
+
+	params := fn.Parameters
+	if len(positional) > len(params) && fn.Rest == nil {
+		return nil, wrongNumberOfArguments(fn, len(positional))
+	}
+
+	n := len(params)
+	if fn.Rest != nil {
+		n++
+	}
+	bound := make([]Object, n)
+	copy(bound, positional)
+
+	for _, k := range keywords {
+		idx := -1
+		for i, p := range params {
+			if p.Value == k.Name {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, &Error{Message: fmt.Sprintf("unexpected keyword argument `%s` to `%s`", k.Name, fn.Signature())}
+		}
+		if bound[idx] != nil {
+			return nil, &Error{Message: fmt.Sprintf("multiple values for argument `%s` to `%s`", k.Name, fn.Signature())}
+		}
+		bound[idx] = k.Value
+	}
+
+	for i, p := range params {
+		if bound[i] != nil || fn.Default(i) != nil {
+			continue
+		}
+		if keywords == nil {
+			return nil, wrongNumberOfArguments(fn, len(positional))
+		}
+		return nil, &Error{Message: fmt.Sprintf("missing argument `%s` to `%s`", p.Value, fn.Signature())}
+	}
+
+	if fn.Rest != nil {
+		rest := []Object{}
+		if len(positional) > len(params) {
+			rest = append(rest, positional[len(params):]...)
+		}
+		bound[len(params)] = &Array{Elements: rest}
+	}
+
+	return bound, nil
+}
+
+// wrong number of arguments to `add(a, b = 1)`: got 3, expected 1 to 2
+func wrongNumberOfArguments(fn *ast.FunctionLiteral, got int) *Error {
+	required := fn.NumRequired()
+
+	var expected string
+	switch {
+	case fn.Rest != nil:
+		expected = fmt.Sprintf("at least %d", required)
+	case required == len(fn.Parameters):
+		expected = fmt.Sprintf("%d", required)
+	default:
+		expected = fmt.Sprintf("%d to %d", required, len(fn.Parameters))
+	}
+
+	return &Error{Message: fmt.Sprintf("wrong number of arguments to `%s`: got %d, expected %s", fn.Signature(), got, expected)}
+}
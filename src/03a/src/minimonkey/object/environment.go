@@ -1,8 +1,17 @@
 package object
 
+import (
+	"context"
+	"sort"
+)
+
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store  map[string]Object
+	consts map[string]bool // constで宣言された名前
+	outer  *Environment
+	ctx    context.Context // ルート環境のみが保持する
+	strict bool            // ルート環境のみが保持する
+	depth  int             // 評価中の関数呼び出しの深さ（ルート環境のみが保持する）
 }
 
 func (e *Environment) Get(name string) (Object, bool) {
@@ -13,11 +22,51 @@ func (e *Environment) Get(name string) (Object, bool) {
 	return val, ok
 }
 
-func (e *Environment) Set(name string, val Object) Object {
+// この環境に名前を束縛する（外側の環境の同じ名前は隠される）
+func (e *Environment) Define(name string, val Object) Object {
 	e.store[name] = val
+	delete(e.consts, name)
 	return val
 }
 
+// この環境に再代入できない名前を束縛する
+func (e *Environment) DefineConst(name string, val Object) Object {
+	e.store[name] = val
+	if e.consts == nil {
+		e.consts = make(map[string]bool)
+	}
+	e.consts[name] = true
+	return val
+}
+
+// この環境で名前が束縛されていればtrueを返す（外側の環境は含まない）
+func (e *Environment) Declared(name string) bool {
+	_, ok := e.store[name]
+	return ok
+}
+
+// 名前が束縛されている最も内側の環境で、constとして宣言されていればtrueを返す
+func (e *Environment) IsConst(name string) bool {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.store[name]; ok {
+			return env.consts[name]
+		}
+	}
+	return false
+}
+
+// 名前が束縛されている最も内側の環境の値を更新する。
+// どの環境にも束縛されていない場合はfalseを返す。
+func (e *Environment) Assign(name string, val Object) (Object, bool) {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.store[name]; ok {
+			env.store[name] = val
+			return val, true
+		}
+	}
+	return nil, false
+}
+
 func NewEnvironment() *Environment {
 	s := make(map[string]Object)
 	return &Environment{store: s}
@@ -27,3 +76,57 @@ func NewEnclosedEnvironment(outer *Environment) *Environment {
 	s := make(map[string]Object)
 	return &Environment{store: s, outer: outer}
 }
+
+// 外側の環境（ルート環境の場合はnil）
+func (e *Environment) Outer() *Environment {
+	return e.outer
+}
+
+// この環境で束縛されている名前を昇順で返す（外側の環境は含まない）
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.store))
+	for name := range e.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// 評価のキャンセルに使用するコンテキストを返す（ルート環境に設定されたものを使用する）
+func (e *Environment) Context() context.Context {
+	for env := e; env != nil; env = env.outer {
+		if env.ctx != nil {
+			return env.ctx
+		}
+	}
+	return context.Background()
+}
+
+func (e *Environment) SetContext(ctx context.Context) {
+	e.ctx = ctx
+}
+
+// 同じスコープでの名前の再宣言をエラーとするか（ルート環境に設定されたものを使用する）。
+// REPLでは定義し直せるよう、既定では許可する。
+func (e *Environment) Strict() bool {
+	return e.root().strict
+}
+
+func (e *Environment) SetStrict(strict bool) {
+	e.strict = strict
+}
+
+// 関数呼び出しの深さにdeltaを加え、変更後の深さを返す（ルート環境で数える）
+func (e *Environment) AddCallDepth(delta int) int {
+	root := e.root()
+	root.depth += delta
+	return root.depth
+}
+
+func (e *Environment) root() *Environment {
+	env := e
+	for env.outer != nil {
+		env = env.outer
+	}
+	return env
+}
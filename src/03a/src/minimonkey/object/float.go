@@ -0,0 +1,86 @@
+package object
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+
+// 小数点または指数を必ず含め、浮動小数点数リテラルとして読み戻せる形式で表示する
+//
+//	3.0  0.1  1e-09  1e+21
+func (f *Float) Inspect() string {
+	v := f.Value
+
+	s := strconv.FormatFloat(v, 'g', -1, 64)
+	if a := math.Abs(v); a == 0 || 1e-4 <= a && a < 1e21 {
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+
+	return s
+}
+
+// IntegerまたはFloatであればtrueを返す
+func IsNumber(obj Object) bool {
+	t := obj.Type()
+	return t == INTEGER_OBJ || t == FLOAT_OBJ
+}
+
+// 数値をfloat64に変換する（BigIntは最も近い値に丸める）
+func ToFloat(obj Object) (float64, bool) {
+	switch obj := obj.(type) {
+	case *Integer:
+		return float64(obj.Value), true
+	case *BigInt:
+		v, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return v, true
+	case *Float:
+		return obj.Value, true
+	}
+	return 0, false
+}
+
+// 浮動小数点数の算術演算（+ - * / %）。
+// 剰余の符号は被除数に合わせる。整数と同様に0による除算はErrorを返す。
+// 結果が無限大になる場合もリテラルとして表せないのでErrorを返す。
+func FloatArithmetic(operator string, left float64, right float64) Object {
+	if right == 0 {
+		switch operator {
+		case "/":
+			return &Error{Message: "division by zero"}
+		case "%":
+			return &Error{Message: "modulo by zero"}
+		}
+	}
+
+	var v float64
+	switch operator {
+	case "+":
+		v = left + right
+	case "-":
+		v = left - right
+	case "*":
+		v = left * right
+	case "/":
+		v = left / right
+	case "%":
+		v = math.Mod(left, right)
+	default:
+		return nil
+	}
+
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return &Error{Message: "float overflow"}
+	}
+	return &Float{Value: v}
+}
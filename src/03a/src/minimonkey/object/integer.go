@@ -0,0 +1,223 @@
+package object
+
+import (
+	"hash/fnv"
+	"math"
+	"math/big"
+)
+
+// int64に収まらない整数。
+// 演算の結果がint64に収まる場合はIntegerに戻すため、値は常にint64の範囲外となる。
+type BigInt struct {
+	Value *big.Int
+}
+
+// Integerと区別せずINTEGERとして扱う
+func (b *BigInt) Type() ObjectType { return INTEGER_OBJ }
+func (b *BigInt) Inspect() string  { return b.Value.String() }
+
+// Integerのキーと衝突しないよう種類を分ける
+func (b *BigInt) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte{byte(b.Value.Sign() + 1)})
+	h.Write(b.Value.Bytes())
+	return HashKey{Type: "BIGINT", Value: h.Sum64()}
+}
+
+// int64に収まる場合はInteger、収まらない場合はBigIntを返す
+func NewInteger(v *big.Int) Object {
+	if v.IsInt64() {
+		return &Integer{Value: v.Int64()}
+	}
+	return &BigInt{Value: v}
+}
+
+// IntegerまたはBigIntの値を新しいbig.Intとして返す
+func ToBigInt(obj Object) *big.Int {
+	switch obj := obj.(type) {
+	case *Integer:
+		return big.NewInt(obj.Value)
+	case *BigInt:
+		return new(big.Int).Set(obj.Value)
+	}
+	return nil
+}
+
+// 整数の算術演算（+ - * / %）。
+// int64の範囲を超える場合はBigIntで計算する。除算は0に向かって切り捨て、剰余の符号は被除数に合わせる。
+// 0による除算はErrorを返す。
+func IntegerArithmetic(operator string, left Object, right Object) Object {
+	// BigIntが0となることはない
+	if r, ok := right.(*Integer); ok && r.Value == 0 {
+		switch operator {
+		case "/":
+			return &Error{Message: "division by zero"}
+		case "%":
+			return &Error{Message: "modulo by zero"}
+		}
+	}
+
+	if l, ok := left.(*Integer); ok {
+		if r, ok := right.(*Integer); ok {
+			if v, ok := int64Arithmetic(operator, l.Value, r.Value); ok {
+				return &Integer{Value: v}
+			}
+		}
+	}
+
+	lv, rv := ToBigInt(left), ToBigInt(right)
+
+	switch operator {
+	case "+":
+		return NewInteger(lv.Add(lv, rv))
+	case "-":
+		return NewInteger(lv.Sub(lv, rv))
+	case "*":
+		return NewInteger(lv.Mul(lv, rv))
+	case "/":
+		return NewInteger(lv.Quo(lv, rv))
+	case "%":
+		return NewInteger(lv.Rem(lv, rv))
+	}
+
+	return nil
+}
+
+// オーバーフローする場合はfalseを返す
+func int64Arithmetic(operator string, a int64, b int64) (int64, bool) {
+	switch operator {
+	case "+":
+		if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+			return 0, false
+		}
+		return a + b, true
+	case "-":
+		if (b < 0 && a > math.MaxInt64+b) || (b > 0 && a < math.MinInt64+b) {
+			return 0, false
+		}
+		return a - b, true
+	case "*":
+		if a == 0 || b == 0 {
+			return 0, true
+		}
+		c := a * b
+		if c/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+			return 0, false
+		}
+		return c, true
+	case "/":
+		if a == math.MinInt64 && b == -1 {
+			return 0, false
+		}
+		return a / b, true
+	case "%":
+		return a % b, true
+	}
+	return 0, false
+}
+
+// 左シフトのシフト数の上限（巨大なBigIntの生成を防ぐ）
+const maxShift = 1 << 20
+
+// 整数のビット演算（& | ^ << >>）。
+// 負の数は2の補数表現として扱う。左シフトの結果がint64に収まらない場合はBigIntとなる。
+// シフト数が負の場合はErrorを返す。
+func IntegerBitwise(operator string, left Object, right Object) Object {
+	switch operator {
+	case "<<", ">>":
+		return shiftInteger(operator, left, right)
+	}
+
+	if l, ok := left.(*Integer); ok {
+		if r, ok := right.(*Integer); ok {
+			switch operator {
+			case "&":
+				return &Integer{Value: l.Value & r.Value}
+			case "|":
+				return &Integer{Value: l.Value | r.Value}
+			case "^":
+				return &Integer{Value: l.Value ^ r.Value}
+			}
+		}
+	}
+
+	lv, rv := ToBigInt(left), ToBigInt(right)
+
+	switch operator {
+	case "&":
+		return NewInteger(lv.And(lv, rv))
+	case "|":
+		return NewInteger(lv.Or(lv, rv))
+	case "^":
+		return NewInteger(lv.Xor(lv, rv))
+	}
+
+	return nil
+}
+
+func shiftInteger(operator string, left Object, right Object) Object {
+	if CompareIntegers(right, &Integer{Value: 0}) < 0 {
+		return &Error{Message: "negative shift count: " + right.Inspect()}
+	}
+
+	lv := ToBigInt(left)
+
+	n, ok := right.(*Integer)
+	if !ok || operator == "<<" && n.Value > maxShift {
+		if operator == ">>" {
+			// すべてのビットがシフトアウトされる
+			return &Integer{Value: int64(lv.Sign() >> 1)}
+		}
+		return &Error{Message: "shift count too large: " + right.Inspect()}
+	}
+
+	if operator == ">>" {
+		if l, ok := left.(*Integer); ok {
+			if n.Value >= 64 {
+				return &Integer{Value: l.Value >> 63}
+			}
+			return &Integer{Value: l.Value >> uint(n.Value)}
+		}
+		return NewInteger(lv.Rsh(lv, uint(n.Value)))
+	}
+
+	return NewInteger(lv.Lsh(lv, uint(n.Value)))
+}
+
+// ビットを反転する（~xは-x-1となる）
+func NotInteger(obj Object) Object {
+	if i, ok := obj.(*Integer); ok {
+		return &Integer{Value: ^i.Value}
+	}
+
+	v := ToBigInt(obj)
+	return NewInteger(v.Not(v))
+}
+
+// 整数の比較（left < rightであれば-1、等しければ0、left > rightであれば1）
+func CompareIntegers(left Object, right Object) int {
+	if l, ok := left.(*Integer); ok {
+		if r, ok := right.(*Integer); ok {
+			switch {
+			case l.Value < r.Value:
+				return -1
+			case l.Value > r.Value:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+
+	return ToBigInt(left).Cmp(ToBigInt(right))
+}
+
+// 符号を反転する（-9223372036854775808の反転はBigIntとなる）
+func NegateInteger(obj Object) Object {
+	if i, ok := obj.(*Integer); ok && i.Value != math.MinInt64 {
+		return &Integer{Value: -i.Value}
+	}
+
+	v := ToBigInt(obj)
+	return NewInteger(v.Neg(v))
+}
@@ -0,0 +1,97 @@
+package object
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// for文や組み込み関数（map, filter, take）で要素を順に取り出せるオブジェクト
+type Iterable interface {
+	Object
+	Iterator() Iterator
+}
+
+// 要素を1つずつ返し、要素がなくなるとfalseを返す。
+// 関数を呼び出して要素を作るIteratorは、関数がエラーとなった場合にその*Errorを要素として返す。
+// Iterator自身もIterableで、同じIteratorを複数の場所で使用すると要素を分け合う。
+type Iterator interface {
+	Iterable
+	Next() (Object, bool)
+}
+
+type funcIterator struct {
+	next func() (Object, bool)
+}
+
+func (it *funcIterator) Type() ObjectType     { return ITERATOR_OBJ }
+func (it *funcIterator) Inspect() string      { return "iterator" }
+func (it *funcIterator) Iterator() Iterator   { return it }
+func (it *funcIterator) Next() (Object, bool) { return it.next() }
+
+// nextが返す要素を順に返すIterator
+func NewIterator(next func() (Object, bool)) Iterator {
+	return &funcIterator{next: next}
+}
+
+// 要素を返さないIterator
+func EmptyIterator() Iterator {
+	return NewIterator(func() (Object, bool) { return nil, false })
+}
+
+// 要素を先頭から順に返す（反復の開始時点の要素を使用する）
+func (a *Array) Iterator() Iterator {
+	elements := a.Elements
+	i := 0
+	return NewIterator(func() (Object, bool) {
+		if i >= len(elements) {
+			return nil, false
+		}
+		i += 1
+		return elements[i-1], true
+	})
+}
+
+// 1文字ずつの文字列を返す
+func (s *String) Iterator() Iterator {
+	rest := s.Value
+	return NewIterator(func() (Object, bool) {
+		if rest == "" {
+			return nil, false
+		}
+		_, size := utf8.DecodeRuneInString(rest)
+		ch := rest[:size]
+		rest = rest[size:]
+		return &String{Value: ch}, true
+	})
+}
+
+// キーを挿入順に返す
+func (h *Hash) Iterator() Iterator {
+	pairs := h.Pairs()
+	i := 0
+	return NewIterator(func() (Object, bool) {
+		if i >= len(pairs) {
+			return nil, false
+		}
+		i += 1
+		return pairs[i-1].Key, true
+	})
+}
+
+// 反復可能なオブジェクトのIteratorを返す。
+// Iterableに加えて、整数nは0からn-1までの範囲として扱う。
+func Iterate(obj Object) (Iterator, *Error) {
+	switch obj := obj.(type) {
+	case Iterable:
+		return obj.Iterator(), nil
+	case *Integer:
+		return (&Range{Start: 0, End: obj.Value}).Iterator(), nil
+	case *BigInt:
+		if obj.Value.Sign() < 0 {
+			return EmptyIterator(), nil
+		}
+		return nil, &Error{Message: fmt.Sprintf("integer range too large: %s", obj.Inspect())}
+	}
+
+	return nil, &Error{Message: fmt.Sprintf("cannot iterate over %s", obj.Type())}
+}
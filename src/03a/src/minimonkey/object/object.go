@@ -2,7 +2,11 @@ package object
 
 import "bytes"
 import "fmt"
+import "hash/fnv"
 import "minimonkey/ast"
+import "minimonkey/code"
+import "minimonkey/token"
+import "strconv"
 import "strings"
 
 type ObjectType string
@@ -11,8 +15,23 @@ const (
 	NULL_OBJ         = "NULL"
 	ERROR_OBJ        = "ERROR"
 	INTEGER_OBJ      = "INTEGER"
+	FLOAT_OBJ        = "FLOAT"
+	BOOLEAN_OBJ      = "BOOLEAN"
+	STRING_OBJ       = "STRING"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
+	BREAK_OBJ        = "BREAK"
+	CONTINUE_OBJ     = "CONTINUE"
 	FUNCTION_OBJ     = "FUNCTION"
+	ARRAY_OBJ        = "ARRAY"
+	HASH_OBJ         = "HASH"
+	BUILTIN_OBJ      = "BUILTIN"
+	RANGE_OBJ        = "RANGE"
+	ITERATOR_OBJ     = "ITERATOR"
+
+	COMPILED_FUNCTION_OBJ = "COMPILED_FUNCTION"
+	CELL_OBJ              = "CELL"
+	SPREAD_OBJ            = "SPREAD"
+	KEYWORD_OBJ           = "KEYWORD"
 )
 
 type Object interface {
@@ -20,6 +39,17 @@ type Object interface {
 	Inspect() string
 }
 
+// ハッシュのキーとして使用できるオブジェクト
+type Hashable interface {
+	Object
+	HashKey() HashKey
+}
+
+type HashKey struct {
+	Type  ObjectType
+	Value uint64
+}
+
 type Null struct{}
 
 func (n *Null) Type() ObjectType { return NULL_OBJ }
@@ -34,12 +64,81 @@ func (i *Integer) Inspect() string {
 	return fmt.Sprintf("%d", i.Value)
 }
 
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+}
+
+type Boolean struct {
+	Value bool
+}
+
+func (b *Boolean) Type() ObjectType { return BOOLEAN_OBJ }
+func (b *Boolean) Inspect() string {
+	return fmt.Sprintf("%t", b.Value)
+}
+func (b *Boolean) HashKey() HashKey {
+	var v uint64
+	if b.Value {
+		v = 1
+	}
+	return HashKey{Type: b.Type(), Value: v}
+}
+
+type String struct {
+	Value string
+}
+
+func (s *String) Type() ObjectType { return STRING_OBJ }
+func (s *String) Inspect() string  { return s.Value }
+func (s *String) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(s.Value))
+	return HashKey{Type: s.Type(), Value: h.Sum64()}
+}
+
 type Error struct {
 	Message string
+	Pos     token.Pos    // エラーが発生した位置
+	Stack   []StackFrame // Goのパニックから変換したエラーのみ、内側から順に呼び出し中の関数を保持する
+}
+
+// 関数の呼び出し
+type StackFrame struct {
+	Function string    // 呼び出した関数の式（"fact"など）
+	Pos      token.Pos // 呼び出した位置
 }
 
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
-func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
+
+// スタックトレースがあれば続けて表示する
+//
+//	ERROR: 3:5: internal error: boom
+//	    at f (3:5)
+//	    at g (5:1)
+func (e *Error) Inspect() string {
+	var out bytes.Buffer
+
+	out.WriteString("ERROR: ")
+	out.WriteString(e.Error())
+
+	for _, f := range e.Stack {
+		out.WriteString("\n    at ")
+		out.WriteString(f.Function)
+		out.WriteString(" (")
+		out.WriteString(f.Pos.String())
+		out.WriteString(")")
+	}
+
+	return out.String()
+}
+
+// file:line:col: message
+func (e *Error) Error() string {
+	if !e.Pos.IsValid() {
+		return e.Message
+	}
+	return e.Pos.String() + ": " + e.Message
+}
 
 type ReturnValue struct {
 	Value Object
@@ -48,14 +147,31 @@ type ReturnValue struct {
 func (r *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
 func (r *ReturnValue) Inspect() string  { return r.Value.Inspect() }
 
+// ループの本体から抜ける（ReturnValueと同様にループの境界まで伝播させる）
+type Break struct{}
+
+func (b *Break) Type() ObjectType { return BREAK_OBJ }
+func (b *Break) Inspect() string  { return "break" }
+
+// ループの次の繰り返しに進む
+type Continue struct{}
+
+func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
+func (c *Continue) Inspect() string  { return "continue" }
+
 type Function struct {
 	Parameters []*ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Environment
+	Literal    *ast.FunctionLiteral // デフォルト値と残りの引数を含む定義
 }
 
 func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
 func (f *Function) Inspect() string {
+	if f.Literal != nil {
+		return f.Literal.String()
+	}
+
 	var out bytes.Buffer
 
 	params := make([]string, len(f.Parameters))
@@ -71,3 +187,164 @@ func (f *Function) Inspect() string {
 
 	return out.String()
 }
+
+// コンパイル済みの関数（定数として保持され、実行時にClosureとなる）
+type CompiledFunction struct {
+	Instructions  code.Instructions
+	Positions     code.PosTable
+	NumLocals     int
+	NumParameters int
+	LocalNames    []string // エラーメッセージ用のローカル変数の名前
+	FreeNames     []string // エラーメッセージ用の自由変数の名前
+	Literal       *ast.FunctionLiteral
+}
+
+func (cf *CompiledFunction) Type() ObjectType { return COMPILED_FUNCTION_OBJ }
+func (cf *CompiledFunction) Inspect() string {
+	return fmt.Sprintf("CompiledFunction[%p]", cf)
+}
+
+// 仮想マシンにおける関数（捕捉した自由変数の値を保持する）
+type Closure struct {
+	Fn   *CompiledFunction
+	Free []Object
+}
+
+// 木構造をたどる評価器の関数と区別せずFUNCTIONとして扱う
+func (c *Closure) Type() ObjectType { return FUNCTION_OBJ }
+func (c *Closure) Inspect() string {
+	return c.Fn.Literal.String()
+}
+
+// 仮想マシンにおいて、代入される変数を関数とクロージャで共有するための格納場所
+type Cell struct {
+	Value Object // 未定義の場合はnil
+}
+
+func (c *Cell) Type() ObjectType { return CELL_OBJ }
+func (c *Cell) Inspect() string {
+	if c.Value == nil {
+		return "cell(undefined)"
+	}
+	return "cell(" + c.Value.Inspect() + ")"
+}
+
+type BuiltinFunction func(args ...Object) Object
+
+// 関数オブジェクトを呼び出す（評価器と仮想マシンがそれぞれの方法で提供する）
+type Caller func(fn Object, args ...Object) Object
+
+// 引数の関数を呼び出す組み込み関数（map, filterなど）
+type HigherOrderFunction func(call Caller, args ...Object) Object
+
+// Goで実装された組み込み関数
+type Builtin struct {
+	Name        string
+	Fn          BuiltinFunction
+	HigherOrder HigherOrderFunction // nilでなければFnの代わりに使用する
+}
+
+// 組み込み関数を呼び出す（callは引数の関数の呼び出しに使用する）
+func (b *Builtin) Call(call Caller, args ...Object) Object {
+	for _, arg := range args {
+		if k, ok := arg.(*Keyword); ok {
+			return &Error{Message: fmt.Sprintf("unexpected keyword argument `%s` to `%s`", k.Name, b.Name)}
+		}
+	}
+
+	if b.HigherOrder != nil {
+		return b.HigherOrder(call, args...)
+	}
+	return b.Fn(args...)
+}
+
+func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
+func (b *Builtin) Inspect() string  { return "builtin function " + b.Name }
+
+type Array struct {
+	Elements []Object
+}
+
+func (a *Array) Type() ObjectType { return ARRAY_OBJ }
+func (a *Array) Inspect() string {
+	var out bytes.Buffer
+
+	elements := make([]string, len(a.Elements))
+	for i, e := range a.Elements {
+		elements[i] = inspectElement(e)
+	}
+
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+
+	return out.String()
+}
+
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+// 挿入順を保持するハッシュ
+type Hash struct {
+	pairs map[HashKey]HashPair
+	keys  []HashKey
+}
+
+func NewHash() *Hash {
+	return &Hash{pairs: make(map[HashKey]HashPair)}
+}
+
+func (h *Hash) Type() ObjectType { return HASH_OBJ }
+func (h *Hash) Inspect() string {
+	var out bytes.Buffer
+
+	pairs := make([]string, len(h.keys))
+	for i, pair := range h.Pairs() {
+		pairs[i] = inspectElement(pair.Key) + ": " + inspectElement(pair.Value)
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+func (h *Hash) Get(key Hashable) (Object, bool) {
+	pair, ok := h.pairs[key.HashKey()]
+	if !ok {
+		return nil, false
+	}
+	return pair.Value, true
+}
+
+func (h *Hash) Set(key Hashable, value Object) {
+	hk := key.HashKey()
+	if _, ok := h.pairs[hk]; !ok {
+		h.keys = append(h.keys, hk)
+	}
+	h.pairs[hk] = HashPair{Key: key, Value: value}
+}
+
+func (h *Hash) Len() int {
+	return len(h.keys)
+}
+
+// 挿入順にキーと値の組を返す
+func (h *Hash) Pairs() []HashPair {
+	pairs := make([]HashPair, len(h.keys))
+	for i, k := range h.keys {
+		pairs[i] = h.pairs[k]
+	}
+	return pairs
+}
+
+// 配列やハッシュの要素を表示する（文字列は引用符で囲む）
+func inspectElement(obj Object) string {
+	if s, ok := obj.(*String); ok {
+		return strconv.Quote(s.Value)
+	}
+	return obj.Inspect()
+}
@@ -0,0 +1,75 @@
+package object
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// 整数の範囲。配列を作らずに要素を順に返す。
+// 終端が始端より小さい場合は空の範囲となる。
+type Range struct {
+	Start     int64
+	End       int64
+	Inclusive bool // 終端を含む（..）かどうか（..<は含まない）
+}
+
+func (r *Range) Type() ObjectType { return RANGE_OBJ }
+func (r *Range) Inspect() string {
+	if r.Inclusive {
+		return fmt.Sprintf("%d..%d", r.Start, r.End)
+	}
+	return fmt.Sprintf("%d..<%d", r.Start, r.End)
+}
+
+// 範囲を作る（operatorは".."または"..<"）。
+// 始端と終端はint64に収まる整数でなければならない。
+func NewRange(operator string, start Object, end Object) Object {
+	s, ok := start.(*Integer)
+	if !ok {
+		return &Error{Message: "range bound out of range: " + start.Inspect()}
+	}
+	e, ok := end.(*Integer)
+	if !ok {
+		return &Error{Message: "range bound out of range: " + end.Inspect()}
+	}
+
+	return &Range{Start: s.Value, End: e.Value, Inclusive: operator == ".."}
+}
+
+// 要素の数（int64に収まらない場合があるためbig.Intで返す）
+func (r *Range) Len() *big.Int {
+	n := new(big.Int).Sub(big.NewInt(r.End), big.NewInt(r.Start))
+	if r.Inclusive {
+		n.Add(n, big.NewInt(1))
+	}
+	if n.Sign() < 0 {
+		n.SetInt64(0)
+	}
+	return n
+}
+
+func (r *Range) Iterator() Iterator {
+	last := r.End
+	if !r.Inclusive {
+		if r.End == math.MinInt64 {
+			return EmptyIterator()
+		}
+		last = r.End - 1
+	}
+
+	i := r.Start
+	done := i > last
+	return NewIterator(func() (Object, bool) {
+		if done {
+			return nil, false
+		}
+		v := i
+		if v == last {
+			done = true
+		} else {
+			i += 1
+		}
+		return &Integer{Value: v}, true
+	})
+}
@@ -0,0 +1,179 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"minimonkey/token"
+	"minimonkey/width"
+	"strings"
+)
+
+// 構文解析エラー
+type Error struct {
+	Pos      token.Pos         // エラーの原因となったトークンの開始位置
+	End      token.Pos         // エラーの原因となったトークンの直後の位置
+	Expected []token.TokenType // 期待していたトークン（不明な場合は空）
+	Found    token.Token       // 実際に現れたトークン
+	Message  string
+	Hint     string // 修正方法の提案（ない場合は空）
+}
+
+// file:line:col: message
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Message)
+}
+
+// エラーメッセージに続けて該当するソースコードの行とキャレットによる下線を表示する
+//
+//	1:9: expected next token to be , or ), got ;
+//	    add(1, 2;
+//	            ^
+func (e *Error) Render(src string) string {
+	var out bytes.Buffer
+
+	out.WriteString(e.Error())
+	out.WriteString("\n")
+
+	if line, ok := sourceLine(src, e.Pos.Offset); ok {
+		start := e.Pos.Offset - line.offset
+		end := e.End.Offset - line.offset
+		if end > len(line.text) {
+			end = len(line.text)
+		}
+
+		out.WriteString("    ")
+		out.WriteString(line.text)
+		out.WriteString("\n")
+		out.WriteString("    ")
+		out.WriteString(indentOf(line.text[:start]))
+		out.WriteString(strings.Repeat("^", maxInt(1, width.String(line.text[start:maxInt(start, end)]))))
+		out.WriteString("\n")
+	}
+
+	if e.Hint != "" {
+		out.WriteString("    hint: ")
+		out.WriteString(e.Hint)
+		out.WriteString("\n")
+	}
+
+	return out.String()
+}
+
+// エラーの一覧を表示用に整形する（*Error以外はメッセージのみ表示する）
+func RenderErrors(src string, errors []error) string {
+	var out bytes.Buffer
+
+	for _, err := range errors {
+		if perr, ok := err.(*Error); ok {
+			out.WriteString(perr.Render(src))
+		} else {
+			out.WriteString(err.Error())
+			out.WriteString("\n")
+		}
+	}
+
+	return out.String()
+}
+
+type line struct {
+	text   string
+	offset int // 行頭のバイトオフセット
+}
+
+func sourceLine(src string, offset int) (line, bool) {
+	if offset < 0 || offset > len(src) {
+		return line{}, false
+	}
+
+	start := strings.LastIndexByte(src[:offset], '\n') + 1
+	end := strings.IndexByte(src[offset:], '\n')
+	if end < 0 {
+		end = len(src)
+	} else {
+		end += offset
+	}
+
+	return line{text: strings.TrimRight(src[start:end], "\r"), offset: start}, true
+}
+
+// キャレットの位置を合わせるため、タブはそのままにそれ以外の文字を表示幅の分の空白に置き換える
+func indentOf(s string) string {
+	var out bytes.Buffer
+	for _, r := range s {
+		if r == '\t' {
+			out.WriteRune('\t')
+		} else {
+			out.WriteString(strings.Repeat(" ", width.Rune(r)))
+		}
+	}
+	return out.String()
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func (p *Parser) tokenError(tok token.Token, format string, a ...interface{}) *Error {
+	return &Error{
+		Pos:     tok.Pos,
+		End:     tok.End,
+		Found:   tok,
+		Message: fmt.Sprintf(format, a...),
+	}
+}
+
+func (p *Parser) peekError(expected ...token.TokenType) error {
+	// 字句解析エラーはそのメッセージを報告する
+	if p.peekTokenIs(token.ILLEGAL) {
+		return p.tokenError(p.peekToken, "%s", p.peekToken.Literal)
+	}
+
+	names := make([]string, len(expected))
+	for i, t := range expected {
+		names[i] = string(t)
+	}
+
+	err := p.tokenError(p.peekToken, "expected next token to be %s, got %s", strings.Join(names, " or "), p.peekToken.Type)
+	err.Expected = expected
+	err.Hint = expectedHint(expected, p.peekToken)
+
+	return err
+}
+
+func (p *Parser) noPrefixParseFnError() error {
+	err := p.tokenError(p.curToken, "no prefix parse function for %s found", p.curToken.Type)
+
+	switch p.curToken.Type {
+	case token.ELSE:
+		err.Hint = "`else` must be on the same line as the closing `}` of the if block"
+	case token.RPAREN, token.RBRACE, token.RBRACKET, token.SEMICOLON, token.EOF:
+		err.Hint = "an expression is missing here"
+	}
+
+	return err
+}
+
+var closers = map[token.TokenType]token.TokenType{
+	token.RPAREN:   token.LPAREN,
+	token.RBRACE:   token.LBRACE,
+	token.RBRACKET: token.LBRACKET,
+}
+
+func expectedHint(expected []token.TokenType, found token.Token) string {
+	for _, t := range expected {
+		if open, ok := closers[t]; ok {
+			// 入力の終わりまたは`}`の直前で自動挿入されたセミコロンは長さ0となる
+			if found.Type == token.EOF || found.Type == token.SEMICOLON && found.Pos == found.End {
+				return fmt.Sprintf("unclosed `%s`; add a matching `%s`", open, t)
+			}
+			// 改行により自動挿入されたセミコロン
+			if found.Type == token.SEMICOLON && found.Pos.Line != found.End.Line {
+				return "a line break inserted `;` here; break lines after `,` or an operator instead"
+			}
+		}
+	}
+	return ""
+}
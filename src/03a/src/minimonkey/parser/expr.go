@@ -1,10 +1,12 @@
 package parser
 
 import (
-	"fmt"
+	"errors"
+	"math/big"
 	"minimonkey/ast"
 	"minimonkey/token"
 	"strconv"
+	"strings"
 )
 
 func (p *Parser) parseExpression(precedence int) (ast.Expression, error) {
@@ -13,9 +15,13 @@ func (p *Parser) parseExpression(precedence int) (ast.Expression, error) {
 
 	prefix := p.prefixParseFns[p.curToken.Type]
 	if prefix == nil {
-		return leftExp, fmt.Errorf("no prefix parse function for %s found", p.curToken.Type)
+		return nil, p.noPrefixParseFnError()
 	}
+
 	leftExp, err = prefix()
+	if err != nil {
+		return nil, err
+	}
 
 	for !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
 		infix := p.infixParseFns[p.peekToken.Type]
@@ -26,9 +32,12 @@ func (p *Parser) parseExpression(precedence int) (ast.Expression, error) {
 		p.nextToken()
 
 		leftExp, err = infix(leftExp)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return leftExp, err
+	return leftExp, nil
 }
 
 func (p *Parser) parseIdentifier() (ast.Expression, error) {
@@ -39,15 +48,47 @@ func (p *Parser) parseIntegerLiteral() (ast.Expression, error) {
 	lit := &ast.IntegerLiteral{Token: p.curToken}
 
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse integer %q", p.curToken.Literal)
+	if err == nil {
+		lit.Value = value
+		return lit, nil
 	}
 
-	lit.Value = value
+	// int64に収まらない整数
+	v, ok := new(big.Int).SetString(p.curToken.Literal, 0)
+	if !ok {
+		return nil, p.tokenError(p.curToken, "invalid integer literal %q", p.curToken.Literal)
+	}
+	lit.Big = v
 
 	return lit, nil
 }
 
+func (p *Parser) parseFloatLiteral() (ast.Expression, error) {
+	// 区切りの'_'は字句解析で検査済み
+	value, err := strconv.ParseFloat(strings.ReplaceAll(p.curToken.Literal, "_", ""), 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return nil, p.tokenError(p.curToken, "float literal %q out of range", p.curToken.Literal)
+	}
+	if err != nil {
+		return nil, p.tokenError(p.curToken, "invalid float literal %q", p.curToken.Literal)
+	}
+
+	return &ast.FloatLiteral{Token: p.curToken, Value: value}, nil
+}
+
+func (p *Parser) parseStringLiteral() (ast.Expression, error) {
+	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}, nil
+}
+
+// 字句解析エラー（ILLEGALトークンのLiteralにはエラーメッセージが格納されている）
+func (p *Parser) parseIllegal() (ast.Expression, error) {
+	return nil, p.tokenError(p.curToken, "%s", p.curToken.Literal)
+}
+
+func (p *Parser) parseBoolean() (ast.Expression, error) {
+	return &ast.Boolean{Token: p.curToken, Value: p.curTokenIs(token.TRUE)}, nil
+}
+
 func (p *Parser) parsePrefixExpression() (ast.Expression, error) {
 	exp := &ast.PrefixExpression{
 		Token:    p.curToken,
@@ -101,7 +142,52 @@ func (p *Parser) parseGroupedExpression() (ast.Expression, error) {
 	return exp, nil
 }
 
-// fn(<identifier>...) { <statement>... }
+// if (<expression>) { <statement>... } [else { <statement>... }]
+func (p *Parser) parseIfExpression() (ast.Expression, error) {
+	var err error
+	exp := &ast.IfExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil, p.peekError(token.LPAREN)
+	}
+
+	p.nextToken()
+
+	exp.Condition, err = p.parseExpression(LOWEST)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil, p.peekError(token.RPAREN)
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil, p.peekError(token.LBRACE)
+	}
+
+	exp.Consequence, err = p.parseBlockStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peekTokenIs(token.ELSE) {
+		p.nextToken() // curToken == ELSE
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil, p.peekError(token.LBRACE)
+		}
+
+		exp.Alternative, err = p.parseBlockStatement()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return exp, nil
+}
+
+// fn(<identifier>, <identifier> = <expression>, ...<identifier>) { <statement>... }
 func (p *Parser) parseFunctionLiteral() (ast.Expression, error) {
 	var err error
 	lit := &ast.FunctionLiteral{Token: p.curToken}
@@ -110,8 +196,7 @@ func (p *Parser) parseFunctionLiteral() (ast.Expression, error) {
 		return nil, p.peekError(token.LPAREN)
 	}
 
-	lit.Parameters, err = p.parseFunctionParameters()
-	if err != nil {
+	if err := p.parseFunctionParameters(lit); err != nil {
 		return nil, err
 	}
 
@@ -119,6 +204,11 @@ func (p *Parser) parseFunctionLiteral() (ast.Expression, error) {
 		return nil, p.peekError(token.LBRACE)
 	}
 
+	// 関数の本体から外側のループをbreakすることはできない
+	loops := p.loops
+	p.loops = 0
+	defer func() { p.loops = loops }()
+
 	lit.Body, err = p.parseBlockStatement()
 	if err != nil {
 		return nil, err
@@ -127,37 +217,77 @@ func (p *Parser) parseFunctionLiteral() (ast.Expression, error) {
 	return lit, nil
 }
 
-func (p *Parser) parseFunctionParameters() ([]*ast.Identifier, error) {
-	identifiers := []*ast.Identifier{}
+func (p *Parser) parseFunctionParameters(lit *ast.FunctionLiteral) error {
+	lit.Parameters = []*ast.Identifier{}
+	lit.Defaults = []ast.Expression{}
+	declared := map[string]bool{}
 
 	// fn() { ... }
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken() // curToken == RPAREN
-		return identifiers, nil
+		return nil
 	}
 
-	if !p.expectPeek(token.IDENT) {
-		return nil, p.peekError(token.IDENT)
-	}
+	for {
+		// ...rest は最後の引数に限る
+		if p.peekTokenIs(token.ELLIPSIS) {
+			p.nextToken() // curToken == ELLIPSIS
+			if !p.expectPeek(token.IDENT) {
+				return p.peekError(token.IDENT)
+			}
+			if declared[p.curToken.Literal] {
+				return p.tokenError(p.curToken, "duplicate parameter: %s", p.curToken.Literal)
+			}
+			lit.Rest = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+			if p.peekTokenIs(token.COMMA) {
+				return p.tokenError(p.peekToken, "rest parameter must be last")
+			}
+			if !p.expectPeek(token.RPAREN) {
+				return p.peekError(token.RPAREN)
+			}
+			return nil
+		}
 
-	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	identifiers = append(identifiers, ident)
+		if !p.expectPeek(token.IDENT) {
+			return p.peekError(token.IDENT, token.ELLIPSIS)
+		}
+		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		if declared[ident.Value] {
+			return p.tokenError(p.curToken, "duplicate parameter: %s", ident.Value)
+		}
+		declared[ident.Value] = true
+
+		var value ast.Expression
+		if p.peekTokenIs(token.ASSIGN) {
+			p.nextToken() // curToken == ASSIGN
+			p.nextToken()
+			exp, err := p.parseExpression(LOWEST)
+			if err != nil {
+				return err
+			}
+			value = exp
+		} else if len(lit.Parameters) > lit.NumRequired() {
+			return p.tokenError(ident.Token, "parameter without default follows parameter with default: %s", ident.Value)
+		}
 
-	for p.peekTokenIs(token.COMMA) {
+		lit.Parameters = append(lit.Parameters, ident)
+		lit.Defaults = append(lit.Defaults, value)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
 		p.nextToken() // curToken == COMMA
-		p.nextToken() // curToken == IDENT
-		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-		identifiers = append(identifiers, ident)
 	}
 
 	if !p.expectPeek(token.RPAREN) {
-		return nil, p.peekError(token.RPAREN)
+		return p.peekError(token.COMMA, token.RPAREN)
 	}
 
-	return identifiers, nil
+	return nil
 }
 
-// <identifier or function_literal or integer_literal>(expression...)
+// <expression>(<expression>, ...<expression>, <identifier>: <expression>)
 func (p *Parser) parseCallExpression(function ast.Expression) (ast.Expression, error) {
 	exp := &ast.CallExpression{Token: p.curToken, Function: function}
 	args, err := p.parseCallArguments()
@@ -165,15 +295,76 @@ func (p *Parser) parseCallExpression(function ast.Expression) (ast.Expression, e
 		return nil, err
 	}
 	exp.Arguments = args
+	exp.Rparen = p.curToken.Pos
 	return exp, nil
 }
 
+// キーワード引数は位置による引数の後に限る
 func (p *Parser) parseCallArguments() ([]ast.Expression, error) {
 	args := []ast.Expression{}
+	keywords := map[string]bool{}
 
-	if p.peekTokenIs(token.RPAREN) {
-		p.nextToken() // curToken == RPAREN
-		return args, nil
+	for !p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		start := p.curToken
+
+		var arg ast.Expression
+		if p.curTokenIs(token.ELLIPSIS) {
+			spread := &ast.SpreadExpression{Token: p.curToken}
+			p.nextToken()
+			value, err := p.parseExpression(LOWEST)
+			if err != nil {
+				return nil, err
+			}
+			spread.Value = value
+			arg = spread
+		} else {
+			exp, err := p.parseExpression(LOWEST)
+			if err != nil {
+				return nil, err
+			}
+			arg = exp
+		}
+
+		if ident, ok := arg.(*ast.Identifier); ok && p.peekTokenIs(token.COLON) {
+			if keywords[ident.Value] {
+				return nil, p.tokenError(ident.Token, "duplicate keyword argument: %s", ident.Value)
+			}
+			keywords[ident.Value] = true
+
+			p.nextToken() // curToken == COLON
+			p.nextToken()
+			value, err := p.parseExpression(LOWEST)
+			if err != nil {
+				return nil, err
+			}
+			arg = &ast.KeywordArgument{Name: ident, Value: value}
+		} else if len(keywords) > 0 {
+			return nil, p.tokenError(start, "positional argument follows keyword argument")
+		}
+
+		args = append(args, arg)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken() // curToken == COMMA（末尾のカンマを許可する）
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil, p.peekError(token.COMMA, token.RPAREN)
+	}
+
+	return args, nil
+}
+
+// <expression>, <expression>, ... <end>
+func (p *Parser) parseExpressionList(end token.TokenType) ([]ast.Expression, error) {
+	list := []ast.Expression{}
+
+	if p.peekTokenIs(end) {
+		p.nextToken() // curToken == end
+		return list, nil
 	}
 
 	p.nextToken()
@@ -181,21 +372,107 @@ func (p *Parser) parseCallArguments() ([]ast.Expression, error) {
 	if err != nil {
 		return nil, err
 	}
-	args = append(args, exp)
+	list = append(list, exp)
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken() // curToken == COMMA
+
+		// 末尾のカンマを許可する
+		if p.peekTokenIs(end) {
+			break
+		}
+
 		p.nextToken()
 		exp, err := p.parseExpression(LOWEST)
 		if err != nil {
 			return nil, err
 		}
-		args = append(args, exp)
+		list = append(list, exp)
 	}
 
-	if !p.expectPeek(token.RPAREN) {
-		return nil, p.peekError(token.RPAREN)
+	if !p.expectPeek(end) {
+		return nil, p.peekError(token.COMMA, end)
 	}
 
-	return args, nil
+	return list, nil
+}
+
+// [<expression>, ...]
+func (p *Parser) parseArrayLiteral() (ast.Expression, error) {
+	array := &ast.ArrayLiteral{Token: p.curToken}
+
+	elements, err := p.parseExpressionList(token.RBRACKET)
+	if err != nil {
+		return nil, err
+	}
+	array.Elements = elements
+	array.Rbrack = p.curToken.Pos
+
+	return array, nil
+}
+
+// {<expression>: <expression>, ...}
+func (p *Parser) parseHashLiteral() (ast.Expression, error) {
+	hash := &ast.HashLiteral{Token: p.curToken}
+	hash.Pairs = []ast.HashPair{}
+
+	for !p.peekTokenIs(token.RBRACE) && !p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+		key, err := p.parseExpression(LOWEST)
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.expectPeek(token.COLON) {
+			return nil, p.peekError(token.COLON)
+		}
+
+		p.nextToken()
+		value, err := p.parseExpression(LOWEST)
+		if err != nil {
+			return nil, err
+		}
+
+		hash.Pairs = append(hash.Pairs, ast.HashPair{Key: key, Value: value})
+
+		if p.peekTokenIs(token.RBRACE) || p.peekTokenIs(token.SEMICOLON) {
+			break
+		}
+
+		if !p.expectPeek(token.COMMA) {
+			return nil, p.peekError(token.COMMA, token.RBRACE)
+		}
+	}
+
+	// `}`の直前に自動挿入されたセミコロンを読み飛ばす
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil, p.peekError(token.RBRACE)
+	}
+	hash.Rbrace = p.curToken.Pos
+
+	return hash, nil
+}
+
+// <expression>[<expression>]
+func (p *Parser) parseIndexExpression(left ast.Expression) (ast.Expression, error) {
+	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
+
+	p.nextToken()
+
+	index, err := p.parseExpression(LOWEST)
+	if err != nil {
+		return nil, err
+	}
+	exp.Index = index
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil, p.peekError(token.RBRACKET)
+	}
+	exp.Rbrack = p.curToken.Pos
+
+	return exp, nil
 }
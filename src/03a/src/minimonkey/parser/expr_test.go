@@ -48,7 +48,7 @@ func testIdentifier(t *testing.T, exp ast.Expression, value string) bool {
 	}
 
 	if ident.Value != value {
-		t.Errorf("ident.Value is %d, expect %d", ident.Value, value)
+		t.Errorf("ident.Value is %s, expect %s", ident.Value, value)
 		return false
 	}
 
@@ -110,6 +110,7 @@ func TestParsingPrefixOperator(t *testing.T) {
 		integerValue int64
 	}{
 		{"-15", "-", 15},
+		{"!5", "!", 5},
 	}
 
 	for _, tt := range tests {
@@ -179,6 +180,12 @@ func TestParsingInfixOperator(t *testing.T) {
 		{"5 - 5", 5, "-", 5},
 		{"5 * 5", 5, "*", 5},
 		{"5 / 5", 5, "/", 5},
+		{"5 == 5", 5, "==", 5},
+		{"5 != 5", 5, "!=", 5},
+		{"5 < 5", 5, "<", 5},
+		{"5 > 5", 5, ">", 5},
+		{"5 <= 5", 5, "<=", 5},
+		{"5 >= 5", 5, ">=", 5},
 	}
 
 	for _, tt := range tests {
@@ -239,6 +246,28 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 		{"-(5 + 5)", "(-(5 + 5));"},
 		{"6 / 2 * (1 + 2)", "((6 / 2) * (1 + 2));"},
 		{"6 / (2 * (1 + 2))", "(6 / (2 * (1 + 2)));"},
+		{"!-a", "(!(-a));"},
+		{"a + b < c * d == true", "(((a + b) < (c * d)) == true);"},
+		{"3 > 5 == false", "((3 > 5) == false);"},
+		{"1 <= 2 != 3 >= 4", "((1 <= 2) != (3 >= 4));"},
+		{"!(true == true)", "(!(true == true));"},
+		{"a * [1, 2, 3, 4][b * c] * d", "((a * ([1,2,3,4][(b * c)])) * d);"},
+		{"add(a * b[2], b[1], 2 * [1, 2][1])", "add((a * (b[2])),(b[1]),(2 * ([1,2][1])));"},
+		{"f(x)[0]", "(f(x)[0]);"},
+		{"a + b % c", "(a + (b % c));"},
+		{"a % b * c", "((a % b) * c);"},
+		{"-a % b", "((-a) % b);"},
+		{"a % b == c", "((a % b) == c);"},
+		{"-1.5 * 2e3", "((-1.5) * 2e3);"},
+		{"a | b & c", "(a | (b & c));"},
+		{"a ^ b << 2", "(a ^ (b << 2));"},
+		{"a + b >> c", "(a + (b >> c));"},
+		{"a & b == c", "((a & b) == c);"},
+		{"~a & b", "((~a) & b);"},
+		{"a << 1 < b", "((a << 1) < b);"},
+		{"0..n - 1", "(0 .. (n - 1));"},
+		{"a..<b * 2 == c", "((a ..< (b * 2)) == c);"},
+		{"a < 1..b", "(a < (1 .. b));"},
 	}
 
 	for _, tt := range tests {
@@ -267,6 +296,8 @@ func testLiteralExpression(t *testing.T, exp ast.Expression, expected interface{
 		return testIntegerLiteral(t, exp, v)
 	case string:
 		return testIdentifier(t, exp, v)
+	case bool:
+		return testBooleanLiteral(t, exp, v)
 	}
 
 	t.Errorf("type of exp not handled. got %T", exp)
@@ -274,6 +305,124 @@ func testLiteralExpression(t *testing.T, exp ast.Expression, expected interface{
 	return false
 }
 
+func TestStringLiteral(t *testing.T) {
+	input := `"hello\tworld"`
+
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.Parse()
+
+	checkParseErrors(t, p)
+	if program == nil {
+		t.Fatalf("Parse() returned nil")
+	}
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements contain %d statements, expected %d", len(program.Statements), 1)
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Errorf("program.Statements[0] is %T, expect %s", stmt, "*ast.ExpressionStatement")
+		return
+	}
+
+	sl, ok := stmt.Expression.(*ast.StringLiteral)
+	if !ok {
+		t.Errorf("stmt.Expression is %T, expect %s", stmt.Expression, "*ast.StringLiteral")
+		return
+	}
+	if sl.Value != "hello\tworld" {
+		t.Errorf("sl.Value is %q, expect %q", sl.Value, "hello\tworld")
+	}
+	if sl.String() != `"hello\tworld"` {
+		t.Errorf("sl.String() is %s, expect %s", sl.String(), `"hello\tworld"`)
+	}
+}
+
+func TestIllegalToken(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"abc`, "1:1: unterminated string literal"},
+		{`let s = "\z"`, "1:9: unknown escape sequence \\z in string literal"},
+		{`1 + #`, "1:5: illegal character '#'"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		p.Parse()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("parser has no errors for %q", tt.input)
+			continue
+		}
+
+		if errors[0].Error() != tt.expected {
+			t.Errorf("errors[0] is %q, expect %q", errors[0].Error(), tt.expected)
+		}
+	}
+}
+
+func TestBooleanLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"true", true},
+		{"false", false},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.Parse()
+
+		checkParseErrors(t, p)
+		if program == nil {
+			t.Fatalf("Parse() returned nil")
+		}
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements contain %d statements, expected %d", len(program.Statements), 1)
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Errorf("program.Statements[0] is %T, expect %s", stmt, "*ast.ExpressionStatement")
+			return
+		}
+
+		testBooleanLiteral(t, stmt.Expression, tt.expected)
+	}
+}
+
+func testBooleanLiteral(t *testing.T, exp ast.Expression, value bool) bool {
+	b, ok := exp.(*ast.Boolean)
+	if !ok {
+		t.Errorf("ast.Expression is %T, expect %s", exp, "*ast.Boolean")
+		return false
+	}
+
+	if b.Value != value {
+		t.Errorf("b.Value is %t, expect %t", b.Value, value)
+		return false
+	}
+
+	if b.TokenLiteral() != strconv.FormatBool(value) {
+		t.Errorf("b.TokenLiteral() is %s, expect %s", b.TokenLiteral(), strconv.FormatBool(value))
+		return false
+	}
+
+	return true
+}
+
 func testInfixExpression(t *testing.T, exp ast.Expression, left interface{}, op string, right interface{}) bool {
 	opExp, ok := exp.(*ast.InfixExpression)
 	if !ok {
@@ -297,6 +446,67 @@ func testInfixExpression(t *testing.T, exp ast.Expression, left interface{}, op
 	return true
 }
 
+func TestIfExpression(t *testing.T) {
+	tests := []struct {
+		input       string
+		consequence string
+		alternative string
+	}{
+		{"if (x < y) { x }", "{ x; }", ""},
+		{"if (x < y) { x } else { y }", "{ x; }", "{ y; }"},
+		{"if (x < y) {\n  x\n} else {\n  y\n}", "{ x; }", "{ y; }"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.Parse()
+		if program == nil {
+			t.Fatalf("Parse() returned nil")
+		}
+
+		checkParseErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements contain %d statements, expected %d", len(program.Statements), 1)
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Errorf("program.Statements[0] is %T, expect %s", stmt, "*ast.ExpressionStatement")
+			return
+		}
+
+		exp, ok := stmt.Expression.(*ast.IfExpression)
+		if !ok {
+			t.Errorf("stmt.Expression is %T, expect %s", stmt.Expression, "*ast.IfExpression")
+			return
+		}
+
+		if !testInfixExpression(t, exp.Condition, "x", "<", "y") {
+			return
+		}
+
+		if exp.Consequence.String() != tt.consequence {
+			t.Errorf("exp.Consequence.String() is %s, expect %s", exp.Consequence.String(), tt.consequence)
+		}
+
+		if tt.alternative == "" {
+			if exp.Alternative != nil {
+				t.Errorf("exp.Alternative is %s, expect nil", exp.Alternative.String())
+			}
+			continue
+		}
+
+		if exp.Alternative == nil {
+			t.Errorf("exp.Alternative is nil, expect %s", tt.alternative)
+		} else if exp.Alternative.String() != tt.alternative {
+			t.Errorf("exp.Alternative.String() is %s, expect %s", exp.Alternative.String(), tt.alternative)
+		}
+	}
+}
+
 func TestFunctionLiteral(t *testing.T) {
 	tests := []struct {
 		input      string
@@ -370,6 +580,66 @@ func TestFunctionLiteral(t *testing.T) {
 	}
 }
 
+func TestFunctionParameters(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  string
+		signature string
+	}{
+		{"fn(a, b = 1 + 2, ...rest) {}", "fn(a,b = (1 + 2),...rest){};", "fn(a, b = (1 + 2), ...rest)"},
+		{"fn(...xs) { xs }", "fn(...xs){ xs; };", "fn(...xs)"},
+		{"let f = fn(a = [1]) { a }", "let f = fn(a = [1]){ a; };", "f(a = [1])"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.Parse()
+		checkParseErrors(t, p)
+
+		if got := program.String(); got != tt.expected {
+			t.Errorf("%q: program.String() got %q, expected %q", tt.input, got, tt.expected)
+		}
+
+		var fl *ast.FunctionLiteral
+		switch stmt := program.Statements[0].(type) {
+		case *ast.ExpressionStatement:
+			fl = stmt.Expression.(*ast.FunctionLiteral)
+		case *ast.LetStatement:
+			fl = stmt.Value.(*ast.FunctionLiteral)
+		}
+		if got := fl.Signature(); got != tt.signature {
+			t.Errorf("%q: Signature() got %q, expected %q", tt.input, got, tt.signature)
+		}
+	}
+}
+
+func TestFunctionParametersErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn(a, 1) {}", "1:7: expected next token to be IDENT or ..., got INT"},
+		{"fn(a,) {}", "1:6: expected next token to be IDENT or ..., got )"},
+		{"fn(a b) {}", "1:6: expected next token to be , or ), got IDENT"},
+		{"fn(a = 1, b) {}", "1:11: parameter without default follows parameter with default: b"},
+		{"fn(...xs, a) {}", "1:9: rest parameter must be last"},
+		{"fn(...xs = 1) {}", "1:10: expected next token to be ), got ="},
+		{"fn(a, a) {}", "1:7: duplicate parameter: a"},
+		{"f(a: 1, 2)", "1:9: positional argument follows keyword argument"},
+		{"f(a: 1, ...xs)", "1:9: positional argument follows keyword argument"},
+		{"f(a: 1, a: 2)", "1:9: duplicate keyword argument: a"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.Parse()
+
+		if len(p.Errors()) == 0 || p.Errors()[0].Error() != tt.expected {
+			t.Errorf("%q: p.Errors() got %q, expected %q", tt.input, p.Errors(), tt.expected)
+		}
+	}
+}
+
 func TestCallExpression(t *testing.T) {
 	tests := []struct {
 		input     string
@@ -383,6 +653,10 @@ func TestCallExpression(t *testing.T) {
 			"fn() {}(1, 2 * 3, 4 + 5)",
 			[]string{"1", "(2 * 3)", "(4 + 5)"},
 		},
+		{
+			"f(a, ...xs, b: 1 + 2, c: d,)",
+			[]string{"a", "...xs", "b: (1 + 2)", "c: d"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -424,3 +698,108 @@ func TestCallExpression(t *testing.T) {
 		}
 	}
 }
+
+func TestArrayLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		elements []string
+	}{
+		{"[]", []string{}},
+		{"[1, 2 * 2, 3 + 3]", []string{"1", "(2 * 2)", "(3 + 3)"}},
+		{"[\n  1,\n  \"a\",\n]", []string{"1", `"a"`}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.Parse()
+		if program == nil {
+			t.Fatalf("Parse() returned nil")
+		}
+
+		checkParseErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements contain %d statements, expected %d", len(program.Statements), 1)
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Errorf("program.Statements[0] is %T, expect %s", stmt, "*ast.ExpressionStatement")
+			return
+		}
+
+		array, ok := stmt.Expression.(*ast.ArrayLiteral)
+		if !ok {
+			t.Errorf("stmt.Expression is %T, expect %s", stmt.Expression, "*ast.ArrayLiteral")
+			return
+		}
+
+		if len(array.Elements) != len(tt.elements) {
+			t.Errorf("len(array.Elements) is %d, expect %d", len(array.Elements), len(tt.elements))
+			return
+		}
+
+		for i, e := range tt.elements {
+			if array.Elements[i].String() != e {
+				t.Errorf("array.Elements[%d].String() is %s, expect %s", i, array.Elements[i].String(), e)
+			}
+		}
+	}
+}
+
+func TestHashLiteral(t *testing.T) {
+	tests := []struct {
+		input string
+		pairs [][2]string
+	}{
+		{"{}", [][2]string{}},
+		{`{"one": 1, "two": 2}`, [][2]string{{`"one"`, "1"}, {`"two"`, "2"}}},
+		{`{1: 0 + 1, true: fn(x) { x }}`, [][2]string{{"1", "(0 + 1)"}, {"true", "fn(x){ x; }"}}},
+		{"{\n  \"a\": 1,\n  \"b\": 2\n}", [][2]string{{`"a"`, "1"}, {`"b"`, "2"}}},
+		{"{\n  \"a\": 1,\n}", [][2]string{{`"a"`, "1"}}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.Parse()
+		if program == nil {
+			t.Fatalf("Parse() returned nil")
+		}
+
+		checkParseErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements contain %d statements, expected %d", len(program.Statements), 1)
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Errorf("program.Statements[0] is %T, expect %s", stmt, "*ast.ExpressionStatement")
+			return
+		}
+
+		hash, ok := stmt.Expression.(*ast.HashLiteral)
+		if !ok {
+			t.Errorf("stmt.Expression is %T, expect %s", stmt.Expression, "*ast.HashLiteral")
+			return
+		}
+
+		if len(hash.Pairs) != len(tt.pairs) {
+			t.Errorf("len(hash.Pairs) is %d, expect %d", len(hash.Pairs), len(tt.pairs))
+			return
+		}
+
+		for i, pair := range tt.pairs {
+			if hash.Pairs[i].Key.String() != pair[0] {
+				t.Errorf("hash.Pairs[%d].Key.String() is %s, expect %s", i, hash.Pairs[i].Key.String(), pair[0])
+			}
+			if hash.Pairs[i].Value.String() != pair[1] {
+				t.Errorf("hash.Pairs[%d].Value.String() is %s, expect %s", i, hash.Pairs[i].Value.String(), pair[1])
+			}
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"fmt"
 	"minimonkey/ast"
 	"minimonkey/lexer"
 	"minimonkey/token"
@@ -10,18 +9,37 @@ import (
 const (
 	_ int = iota // 0は未定義とする
 	LOWEST
-	SUM     // +, -
-	PRODUCT // *, /
-	PREFIX  // -X
-	CALL    // ()
+	EQUALS      // ==, !=
+	LESSGREATER // <, >, <=, >=
+	RANGE       // .., ..<
+	SUM         // +, -, |, ^
+	PRODUCT     // *, /, %, &, <<, >>
+	PREFIX      // -X, !X, ~X
+	CALL        // ()
+	INDEX       // []
 )
 
 var precedences = map[token.TokenType]int{
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.ASTERISK: PRODUCT,
-	token.SLASH:    PRODUCT,
-	token.LPAREN:   CALL,
+	token.EQ:        EQUALS,
+	token.NOT_EQ:    EQUALS,
+	token.LT:        LESSGREATER,
+	token.GT:        LESSGREATER,
+	token.LT_EQ:     LESSGREATER,
+	token.GT_EQ:     LESSGREATER,
+	token.PLUS:      SUM,
+	token.MINUS:     SUM,
+	token.ASTERISK:  PRODUCT,
+	token.SLASH:     PRODUCT,
+	token.PERCENT:   PRODUCT,
+	token.PIPE:      SUM,
+	token.CARET:     SUM,
+	token.AMPERSAND: PRODUCT,
+	token.SHL:       PRODUCT,
+	token.SHR:       PRODUCT,
+	token.DOTDOT:    RANGE,
+	token.DOTDOT_LT: RANGE,
+	token.LPAREN:    CALL,
+	token.LBRACKET:  INDEX,
 }
 
 type (
@@ -32,6 +50,8 @@ type (
 type Parser struct {
 	l      *lexer.Lexer
 	errors []error
+	depth  int // curTokenまでの閉じられていない`{`の数
+	loops  int // 解析中のループの本体の入れ子の数（関数リテラルの中では0から数える）
 
 	curToken  token.Token
 	peekToken token.Token
@@ -46,17 +66,42 @@ func New(l *lexer.Lexer) *Parser {
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefixFn(token.IDENT, p.parseIdentifier)
 	p.registerPrefixFn(token.INT, p.parseIntegerLiteral)
+	p.registerPrefixFn(token.FLOAT, p.parseFloatLiteral)
+	p.registerPrefixFn(token.STRING, p.parseStringLiteral)
+	p.registerPrefixFn(token.ILLEGAL, p.parseIllegal)
+	p.registerPrefixFn(token.TRUE, p.parseBoolean)
+	p.registerPrefixFn(token.FALSE, p.parseBoolean)
 	p.registerPrefixFn(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefixFn(token.BANG, p.parsePrefixExpression)
+	p.registerPrefixFn(token.TILDE, p.parsePrefixExpression)
 	p.registerPrefixFn(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefixFn(token.RETURN, p.parseFunctionLiteral)
 	p.registerPrefixFn(token.FUNCTION, p.parseFunctionLiteral)
+	p.registerPrefixFn(token.IF, p.parseIfExpression)
+	p.registerPrefixFn(token.LBRACKET, p.parseArrayLiteral)
+	p.registerPrefixFn(token.LBRACE, p.parseHashLiteral)
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 	p.registerInfixFn(token.PLUS, p.parseInfixExpression)
 	p.registerInfixFn(token.MINUS, p.parseInfixExpression)
 	p.registerInfixFn(token.ASTERISK, p.parseInfixExpression)
 	p.registerInfixFn(token.SLASH, p.parseInfixExpression)
+	p.registerInfixFn(token.PERCENT, p.parseInfixExpression)
+	p.registerInfixFn(token.AMPERSAND, p.parseInfixExpression)
+	p.registerInfixFn(token.PIPE, p.parseInfixExpression)
+	p.registerInfixFn(token.CARET, p.parseInfixExpression)
+	p.registerInfixFn(token.SHL, p.parseInfixExpression)
+	p.registerInfixFn(token.SHR, p.parseInfixExpression)
+	p.registerInfixFn(token.DOTDOT, p.parseInfixExpression)
+	p.registerInfixFn(token.DOTDOT_LT, p.parseInfixExpression)
+	p.registerInfixFn(token.EQ, p.parseInfixExpression)
+	p.registerInfixFn(token.NOT_EQ, p.parseInfixExpression)
+	p.registerInfixFn(token.LT, p.parseInfixExpression)
+	p.registerInfixFn(token.GT, p.parseInfixExpression)
+	p.registerInfixFn(token.LT_EQ, p.parseInfixExpression)
+	p.registerInfixFn(token.GT_EQ, p.parseInfixExpression)
 	p.registerInfixFn(token.LPAREN, p.parseCallExpression)
+	p.registerInfixFn(token.LBRACKET, p.parseIndexExpression)
 
 	// トークンを2つ読み込んで`curToken`と`peekToken`をセットする
 	p.nextToken()
@@ -68,6 +113,15 @@ func New(l *lexer.Lexer) *Parser {
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextToken()
+
+	switch p.curToken.Type {
+	case token.LBRACE:
+		p.depth += 1
+	case token.RBRACE:
+		if p.depth > 0 {
+			p.depth -= 1
+		}
+	}
 }
 
 func (p *Parser) Parse() *ast.Program {
@@ -78,8 +132,10 @@ func (p *Parser) Parse() *ast.Program {
 		stmt, err := p.parseStmt()
 		if err != nil {
 			p.errors = append(p.errors, err)
+			p.synchronize(0)
+		} else {
+			program.Statements = append(program.Statements, stmt)
 		}
-		program.Statements = append(program.Statements, stmt)
 		p.nextToken()
 	}
 
@@ -107,8 +163,15 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 	return false
 }
 
-func (p *Parser) peekError(t token.TokenType) error {
-	return fmt.Errorf("expected next token to be %s, got %s", t, p.peekToken.Type)
+// エラーから回復するため、深さdepthにある次の文の区切り（`;`）または
+// ブロックの終わり（`}`の直前）までトークンを読み飛ばす
+func (p *Parser) synchronize(depth int) {
+	for !p.curTokenIs(token.EOF) && p.depth >= depth {
+		if p.depth == depth && (p.curTokenIs(token.SEMICOLON) || p.peekTokenIs(token.RBRACE)) {
+			return
+		}
+		p.nextToken()
+	}
 }
 
 func (p *Parser) registerPrefixFn(tokenType token.TokenType, fn prefixParseFn) {
@@ -116,6 +116,210 @@ func TestParse(t *testing.T) {
 
 }
 
+func TestParseErrorPosition(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"add(1, 2;", "1:9: expected next token to be , or ), got ;"},
+		{"let x = 1\nlet = 2", "2:5: expected next token to be IDENT, got ="},
+		{"fn(x) {\n  x +\n}", "3:1: no prefix parse function for } found"},
+		{"1 + .5", "1:5: float literal \".5\" must have a digit before the decimal point (use 0.5)"},
+		{"1e400", "1:1: float literal \"1e400\" out of range"},
+		{"1 @ 2", "1:3: illegal character '@'"},
+		{"let x = 1 $", "1:11: illegal character '$'"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewFile("", tt.input)
+		p := New(l)
+
+		p.Parse()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("parser has no errors for %q", tt.input)
+			continue
+		}
+
+		if errors[0].Error() != tt.expected {
+			t.Errorf("errors[0] is %q, expect %q", errors[0].Error(), tt.expected)
+		}
+	}
+
+	p := New(lexer.NewFile("main.mm", "let = 1"))
+	p.Parse()
+	if len(p.Errors()) == 0 || p.Errors()[0].Error() != "main.mm:1:5: expected next token to be IDENT, got =" {
+		t.Errorf("p.Errors() got %q", p.Errors())
+	}
+}
+
+func TestParseErrorRecovery(t *testing.T) {
+	tests := []struct {
+		input      string
+		errors     []string
+		statements []string
+	}{
+		{
+			"let = 1; let x = 2; x",
+			[]string{"1:5: expected next token to be IDENT, got ="},
+			[]string{"let x = 2;", "x;"},
+		},
+		{
+			"let a = (1 + ; let b = 2 +; let c = 3",
+			[]string{
+				"1:14: no prefix parse function for ; found",
+				"1:27: no prefix parse function for ; found",
+			},
+			[]string{"let c = 3;"},
+		},
+		{
+			"let f = fn() {\n  let = 1\n  let y = )\n  2\n}\nf()",
+			[]string{
+				"2:7: expected next token to be IDENT, got =",
+				"3:11: no prefix parse function for ) found",
+			},
+			[]string{"let f = fn(){ 2; };", "f();"},
+		},
+		{
+			"fn() { 1 + }; 2",
+			[]string{"1:12: no prefix parse function for } found"},
+			[]string{"fn(){};", "2;"},
+		},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.Parse()
+
+		errors := p.Errors()
+		if len(errors) != len(tt.errors) {
+			t.Errorf("parser has %d errors for %q, expect %d: %q", len(errors), tt.input, len(tt.errors), errors)
+			continue
+		}
+		for i, msg := range tt.errors {
+			if errors[i].Error() != msg {
+				t.Errorf("errors[%d] is %q, expect %q", i, errors[i].Error(), msg)
+			}
+		}
+
+		if len(program.Statements) != len(tt.statements) {
+			t.Errorf("program.Statements contain %d statements, expected %d", len(program.Statements), len(tt.statements))
+			continue
+		}
+		for i, s := range tt.statements {
+			if program.Statements[i].String() != s {
+				t.Errorf("program.Statements[%d].String() is %q, expect %q", i, program.Statements[i].String(), s)
+			}
+		}
+	}
+}
+
+func TestErrorRender(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"add(1, 2;",
+			"1:9: expected next token to be , or ), got ;\n" +
+				"    add(1, 2;\n" +
+				"            ^\n",
+		},
+		{
+			"let x = 1\n\tlet 123 = x",
+			"2:6: expected next token to be IDENT, got INT\n" +
+				"    \tlet 123 = x\n" +
+				"    \t    ^^^\n",
+		},
+		{
+			"add(1,\n  2\n)",
+			"2:4: expected next token to be , or ), got ;\n" +
+				"      2\n" +
+				"       ^\n" +
+				"    hint: a line break inserted `;` here; break lines after `,` or an operator instead\n",
+		},
+		{
+			"if (x) { 1 }\nelse { 2 }",
+			"2:1: no prefix parse function for ELSE found\n" +
+				"    else { 2 }\n" +
+				"    ^^^^\n" +
+				"    hint: `else` must be on the same line as the closing `}` of the if block\n",
+		},
+		{
+			"let 値段 = (1 + 2",
+			"1:16: expected next token to be ), got ;\n" +
+				"    let 値段 = (1 + 2\n" +
+				"                     ^\n" +
+				"    hint: unclosed `(`; add a matching `)`\n",
+		},
+		{
+			"let x = 値段 値段",
+			"1:12: expected next token to be ;, got IDENT\n" +
+				"    let x = 値段 値段\n" +
+				"                 ^^^^\n",
+		},
+		{
+			"[1, 2",
+			"1:6: expected next token to be , or ], got ;\n" +
+				"    [1, 2\n" +
+				"         ^\n" +
+				"    hint: unclosed `[`; add a matching `]`\n",
+		},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.Parse()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("parser has no errors for %q", tt.input)
+			continue
+		}
+
+		got := RenderErrors(tt.input, p.Errors()[:1])
+		if got != tt.expected {
+			t.Errorf("RenderErrors() got\n%s\nexpect\n%s", got, tt.expected)
+		}
+	}
+}
+
+func TestNodePosition(t *testing.T) {
+	input := `let add = fn(a, b) {
+  a + b
+}
+add(1, [2][0])`
+
+	p := New(lexer.New(input))
+	program := p.Parse()
+	checkParseErrors(t, p)
+
+	tests := []struct {
+		node   ast.Node
+		pos    string
+		end    string
+		offset int
+	}{
+		{program, "1:1", "4:15", 0},
+		{program.Statements[0], "1:1", "3:2", 0},
+		{program.Statements[0].(*ast.LetStatement).Value.(*ast.FunctionLiteral).Body, "1:20", "3:2", 19},
+		{program.Statements[1].(*ast.ExpressionStatement).Expression, "4:1", "4:15", 31},
+		{program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.CallExpression).Arguments[1], "4:8", "4:14", 38},
+	}
+
+	for _, tt := range tests {
+		if tt.node.Pos().String() != tt.pos {
+			t.Errorf("%s: Pos() got %s, expect %s", tt.node.String(), tt.node.Pos(), tt.pos)
+		}
+		if tt.node.End().String() != tt.end {
+			t.Errorf("%s: End() got %s, expect %s", tt.node.String(), tt.node.End(), tt.end)
+		}
+		if tt.node.Pos().Offset != tt.offset {
+			t.Errorf("%s: Pos().Offset got %d, expect %d", tt.node.String(), tt.node.Pos().Offset, tt.offset)
+		}
+	}
+}
+
 func checkParseErrors(t *testing.T, p *Parser) {
 	errors := p.Errors()
 
@@ -7,18 +7,61 @@ import (
 
 func (p *Parser) parseStmt() (ast.Statement, error) {
 	switch p.curToken.Type {
-	case token.LET:
+	case token.LET, token.CONST:
 		return p.parseLetStatement()
 	case token.SEMICOLON:
 		return &ast.EmptyStatement{Token: p.curToken}, nil
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.WHILE:
+		return p.parseWhileStatement()
+	case token.FOR:
+		return p.parseForStatement()
+	case token.BREAK:
+		return p.parseBreakStatement()
+	case token.CONTINUE:
+		return p.parseContinueStatement()
+	case token.IDENT:
+		if assignOperators[p.peekToken.Type] {
+			return p.parseAssignStatement()
+		}
+		return p.parseExpressionStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
 }
 
-// let <identifier> = <expression>;
+var assignOperators = map[token.TokenType]bool{
+	token.ASSIGN:          true,
+	token.PLUS_ASSIGN:     true,
+	token.MINUS_ASSIGN:    true,
+	token.ASTERISK_ASSIGN: true,
+	token.SLASH_ASSIGN:    true,
+}
+
+// <identifier> = <expression>;（=の代わりに+=, -=, *=, /=も使用できる）
+func (p *Parser) parseAssignStatement() (*ast.AssignStatement, error) {
+	name := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	p.nextToken()
+	stmt := &ast.AssignStatement{Token: p.curToken, Name: name, Operator: p.curToken.Literal}
+
+	p.nextToken()
+
+	value, err := p.parseExpression(LOWEST)
+	if err != nil {
+		return nil, err
+	}
+	stmt.Value = value
+
+	if !p.expectPeek(token.SEMICOLON) {
+		return nil, p.peekError(token.SEMICOLON)
+	}
+
+	return stmt, nil
+}
+
+// let <identifier> = <expression>;（constも同じ形式）
 func (p *Parser) parseLetStatement() (*ast.LetStatement, error) {
 	stmt := &ast.LetStatement{Token: p.curToken}
 
@@ -40,6 +83,10 @@ func (p *Parser) parseLetStatement() (*ast.LetStatement, error) {
 	}
 	stmt.Value = value
 
+	if fl, ok := value.(*ast.FunctionLiteral); ok {
+		fl.Name = stmt.Name.Value
+	}
+
 	if !p.expectPeek(token.SEMICOLON) {
 		return nil, p.peekError(token.SEMICOLON)
 	}
@@ -57,6 +104,13 @@ func (p *Parser) parseExpressionStatement() (*ast.ExpressionStatement, error) {
 	}
 	stmt.Expression = exp
 
+	// 代入できるのは変数のみ（a[0] = 1などは不可）
+	if assignOperators[p.peekToken.Type] {
+		err := p.tokenError(p.peekToken, "cannot assign to %s", exp)
+		err.Pos, err.End = exp.Pos(), exp.End()
+		return nil, err
+	}
+
 	if !p.expectPeek(token.SEMICOLON) {
 		return nil, p.peekError(token.SEMICOLON)
 	}
@@ -85,16 +139,142 @@ func (p *Parser) parseReturnStatement() (*ast.ReturnStatement, error) {
 	return stmt, nil
 }
 
+// while (<expression>) { [statement...] }
+func (p *Parser) parseWhileStatement() (*ast.WhileStatement, error) {
+	var err error
+	stmt := &ast.WhileStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil, p.peekError(token.LPAREN)
+	}
+
+	p.nextToken()
+
+	stmt.Condition, err = p.parseExpression(LOWEST)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil, p.peekError(token.RPAREN)
+	}
+
+	stmt.Body, err = p.parseLoopBody()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.expectPeek(token.SEMICOLON) {
+		return nil, p.peekError(token.SEMICOLON)
+	}
+
+	return stmt, nil
+}
+
+// for (<identifier> in <expression>) { [statement...] }
+func (p *Parser) parseForStatement() (*ast.ForStatement, error) {
+	var err error
+	stmt := &ast.ForStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil, p.peekError(token.LPAREN)
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil, p.peekError(token.IDENT)
+	}
+	stmt.Variable = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.IN) {
+		return nil, p.peekError(token.IN)
+	}
+
+	p.nextToken()
+
+	stmt.Iterable, err = p.parseExpression(LOWEST)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil, p.peekError(token.RPAREN)
+	}
+
+	stmt.Body, err = p.parseLoopBody()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.expectPeek(token.SEMICOLON) {
+		return nil, p.peekError(token.SEMICOLON)
+	}
+
+	return stmt, nil
+}
+
+// ループの本体ではbreakとcontinueを使用できる
+func (p *Parser) parseLoopBody() (*ast.BlockStatement, error) {
+	if !p.expectPeek(token.LBRACE) {
+		return nil, p.peekError(token.LBRACE)
+	}
+
+	p.loops += 1
+	defer func() { p.loops -= 1 }()
+
+	return p.parseBlockStatement()
+}
+
+// break;
+func (p *Parser) parseBreakStatement() (*ast.BreakStatement, error) {
+	stmt := &ast.BreakStatement{Token: p.curToken}
+
+	if p.loops == 0 {
+		return nil, p.tokenError(p.curToken, "break is not in a loop")
+	}
+
+	if !p.expectPeek(token.SEMICOLON) {
+		return nil, p.peekError(token.SEMICOLON)
+	}
+
+	return stmt, nil
+}
+
+// continue;
+func (p *Parser) parseContinueStatement() (*ast.ContinueStatement, error) {
+	stmt := &ast.ContinueStatement{Token: p.curToken}
+
+	if p.loops == 0 {
+		return nil, p.tokenError(p.curToken, "continue is not in a loop")
+	}
+
+	if !p.expectPeek(token.SEMICOLON) {
+		return nil, p.peekError(token.SEMICOLON)
+	}
+
+	return stmt, nil
+}
+
 // { [statement...] }
 func (p *Parser) parseBlockStatement() (*ast.BlockStatement, error) {
 	block := &ast.BlockStatement{Token: p.curToken} // curToken == LBRACE
 	block.Statements = []ast.Statement{}
 
+	depth := p.depth
+
 	for !p.peekTokenIs(token.RBRACE) && !p.peekTokenIs(token.EOF) {
 		p.nextToken()
 		stmt, err := p.parseStmt()
 		if err != nil {
-			return nil, err
+			// エラーを記録してブロック内の次の文から解析を続ける
+			p.errors = append(p.errors, err)
+			p.synchronize(depth)
+
+			// ブロックを閉じる`}`まで読み進めてしまった場合
+			if p.depth < depth {
+				block.Rbrace = p.curToken.Pos
+				return block, nil
+			}
+			continue
 		}
 		block.Statements = append(block.Statements, stmt)
 	}
@@ -102,6 +282,7 @@ func (p *Parser) parseBlockStatement() (*ast.BlockStatement, error) {
 	if !p.expectPeek(token.RBRACE) {
 		return nil, p.peekError(token.RBRACE)
 	}
+	block.Rbrace = p.curToken.Pos
 
 	return block, nil
 }
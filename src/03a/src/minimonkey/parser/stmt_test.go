@@ -86,6 +86,125 @@ func TestInvalidStatement(t *testing.T) {
 	}
 }
 
+func TestConstStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		isConst  bool
+		expected string
+	}{
+		{"const x = 5", true, "const x = 5;"},
+		{"const f = fn(a) { a }", true, "const f = fn(a){ a; };"},
+		{"let x = 5", false, "let x = 5;"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.Parse()
+
+		checkParseErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.LetStatement)
+		if !ok {
+			t.Fatalf("stmt is %T, expected *ast.LetStatement", program.Statements[0])
+		}
+
+		if stmt.IsConst() != tt.isConst {
+			t.Errorf("stmt.IsConst() got %t, expected %t", stmt.IsConst(), tt.isConst)
+		}
+		if stmt.String() != tt.expected {
+			t.Errorf("stmt.String() got %q, expected %q", stmt.String(), tt.expected)
+		}
+	}
+}
+
+func TestAssignStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		name     string
+		operator string
+		value    string
+	}{
+		{"x = 5;", "x", "=", "5"},
+		{"x += y * 2", "x", "+=", "(y * 2)"},
+		{"x -= 1", "x", "-=", "1"},
+		{"x *= 2", "x", "*=", "2"},
+		{"x /= f(1)", "x", "/=", "f(1)"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.Parse()
+
+		checkParseErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements contain %d statements, expected 1", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.AssignStatement)
+		if !ok {
+			t.Fatalf("stmt is %T, expected *ast.AssignStatement", program.Statements[0])
+		}
+
+		if stmt.Name.Value != tt.name || stmt.Operator != tt.operator || stmt.Value.String() != tt.value {
+			t.Errorf("stmt got %s %s %s, expected %s %s %s", stmt.Name, stmt.Operator, stmt.Value, tt.name, tt.operator, tt.value)
+		}
+	}
+
+	// 変数以外には代入できない
+	p := New(lexer.New("a[0] = 1"))
+	p.Parse()
+	if len(p.Errors()) == 0 || p.Errors()[0].Error() != "1:1: cannot assign to (a[0])" {
+		t.Errorf("p.Errors() got %q", p.Errors())
+	}
+}
+
+func TestLoopStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"while (x < 10) { x += 1 }", "while (x < 10) { x += 1; };"},
+		{"for (x in xs) { puts(x) }", "for (x in xs) { puts(x); };"},
+		{"for (i in 10) { if (i > 5) { break }; continue }", "for (i in 10) { if (i > 5) { break; }; continue; };"},
+		{"while (true) { for (x in xs) { break }; break }", "while true { for (x in xs) { break; }; break; };"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.Parse()
+
+		checkParseErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("program.String() got %q, expected %q", program.String(), tt.expected)
+		}
+	}
+}
+
+func TestLoopStatementErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"break", "1:1: break is not in a loop"},
+		{"if (true) { continue }", "1:13: continue is not in a loop"},
+		{"while (true) { fn() { break } }", "1:23: break is not in a loop"},
+		{"for (x of xs) { }", "1:8: expected next token to be IN, got IDENT"},
+		{"for (1 in xs) { }", "1:6: expected next token to be IDENT, got INT"},
+		{"while true { }", "1:7: expected next token to be (, got TRUE"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.Parse()
+
+		if len(p.Errors()) == 0 || p.Errors()[0].Error() != tt.expected {
+			t.Errorf("%q: p.Errors() got %q, expected %q", tt.input, p.Errors(), tt.expected)
+		}
+	}
+}
+
 func TestReturnStatement(t *testing.T) {
 	tt := []struct {
 		input    string
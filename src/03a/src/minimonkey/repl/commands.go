@@ -0,0 +1,163 @@
+package repl
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"time"
+
+	"minimonkey/ast"
+	"minimonkey/lexer"
+	"minimonkey/token"
+)
+
+const commandHelp = `:tokens <source>  show the tokens produced by the lexer
+:ast <source>     show the syntax tree
+:env              list the bindings in the current environment
+:reset            discard all bindings
+:load <file>      evaluate a file in the current environment
+:time <source>    evaluate source and show the elapsed time
+:help             show this help
+`
+
+// ":"で始まる行をREPLのコマンドとして実行する
+func (s *session) command(line string) {
+	name, arg := line, ""
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		name, arg = line[:i], strings.TrimSpace(line[i+1:])
+	}
+
+	switch name {
+	case ":tokens":
+		if arg == "" {
+			io.WriteString(s.out, "usage: :tokens <source>\n")
+			return
+		}
+		s.dumpTokens(arg)
+
+	case ":ast":
+		if arg == "" {
+			io.WriteString(s.out, "usage: :ast <source>\n")
+			return
+		}
+		if program := s.parse("", arg); program != nil {
+			dumpNode(s.out, "", program, 0)
+		}
+
+	case ":env":
+		s.dumpEnv()
+
+	case ":reset":
+		s.reset()
+
+	case ":load":
+		if arg == "" {
+			io.WriteString(s.out, "usage: :load <file>\n")
+			return
+		}
+		src, err := ioutil.ReadFile(arg)
+		if err != nil {
+			fmt.Fprintln(s.out, err)
+			return
+		}
+		s.eval(arg, string(src))
+
+	case ":time":
+		if arg == "" {
+			io.WriteString(s.out, "usage: :time <source>\n")
+			return
+		}
+		start := time.Now()
+		s.eval("", arg)
+		fmt.Fprintf(s.out, "elapsed: %s\n", time.Since(start))
+
+	case ":help":
+		io.WriteString(s.out, commandHelp)
+
+	default:
+		fmt.Fprintf(s.out, "unknown command %s; type :help for a list of commands\n", name)
+	}
+}
+
+// 1行に1トークンを位置、種類、リテラルの順に表示する
+func (s *session) dumpTokens(src string) {
+	l := lexer.New(src)
+
+	for {
+		tok := l.NextToken()
+
+		fmt.Fprintf(s.out, "%-6s %-10s %q", tok.Pos, tok.Type, tok.Literal)
+		if isAutoSemicolon(tok) {
+			io.WriteString(s.out, " (inserted)")
+		}
+		io.WriteString(s.out, "\n")
+
+		if tok.Type == token.EOF {
+			return
+		}
+	}
+}
+
+// 束縛を名前の順に表示する
+func (s *session) dumpEnv() {
+	for _, name := range s.in.GlobalNames() {
+		val, _ := s.in.GlobalObject(name)
+		fmt.Fprintf(s.out, "%s = %s\n", name, val.Inspect())
+	}
+}
+
+var nodeType = reflect.TypeOf((*ast.Node)(nil)).Elem()
+
+// ノードの種類と範囲、値を持つフィールドを1行に表示し、子ノードを字下げして続ける
+//
+//	LetStatement 1:1-1:10
+//	  Name: Identifier 1:5-1:6 Value="x"
+//	  Value: IntegerLiteral 1:9-1:10 Value=1
+func dumpNode(out io.Writer, label string, node ast.Node, depth int) {
+	v := reflect.ValueOf(node).Elem()
+
+	fmt.Fprintf(out, "%s%s%s %s-%s", strings.Repeat("  ", depth), label, v.Type().Name(), node.Pos(), node.End())
+
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.String:
+			fmt.Fprintf(out, " %s=%q", v.Type().Field(i).Name, f.String())
+		case reflect.Int64, reflect.Float64, reflect.Bool:
+			fmt.Fprintf(out, " %s=%v", v.Type().Field(i).Name, f.Interface())
+		case reflect.Ptr:
+			// ノードではない値（*big.Intなど）は文字列として表示する
+			if s, ok := f.Interface().(fmt.Stringer); ok && !f.Type().Implements(nodeType) && !f.IsNil() {
+				fmt.Fprintf(out, " %s=%s", v.Type().Field(i).Name, s)
+			}
+		}
+	}
+	io.WriteString(out, "\n")
+
+	for i := 0; i < v.NumField(); i++ {
+		dumpField(out, v.Type().Field(i).Name, v.Field(i), depth+1)
+	}
+}
+
+func dumpField(out io.Writer, name string, f reflect.Value, depth int) {
+	switch {
+	case f.Type().Implements(nodeType):
+		if !f.IsNil() {
+			dumpNode(out, name+": ", f.Interface().(ast.Node), depth)
+		}
+
+	case f.Kind() == reflect.Slice:
+		for i := 0; i < f.Len(); i++ {
+			dumpField(out, fmt.Sprintf("%s[%d]", name, i), f.Index(i), depth)
+		}
+
+	// HashPairなどノードではない構造体（トークンと位置は除く）
+	case f.Kind() == reflect.Struct && f.Type().PkgPath() != reflect.TypeOf(token.Token{}).PkgPath():
+		fmt.Fprintf(out, "%s%s:\n", strings.Repeat("  ", depth), name)
+		for i := 0; i < f.NumField(); i++ {
+			dumpField(out, f.Type().Field(i).Name, f.Field(i), depth+1)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"unicode"
+
+	"minimonkey/width"
+)
+
+// rawモードの端末で動作する簡易的な行エディタ
+//
+//	←/→, Ctrl-B/Ctrl-F       カーソルの移動
+//	Home/End, Ctrl-A/Ctrl-E  行頭/行末へ移動
+//	↑/↓, Ctrl-P/Ctrl-N       履歴の参照
+//	Backspace, Delete        文字の削除
+//	Ctrl-K/Ctrl-U/Ctrl-W     カーソル以降/以前/直前の単語を削除
+//	Ctrl-L                   画面のクリア
+//	Ctrl-C                   入力中の行を破棄
+//	Ctrl-D                   空行であれば終了
+type editor struct {
+	in      *bufio.Reader
+	out     io.Writer
+	history []string
+}
+
+func newEditor(in io.Reader, out io.Writer) *editor {
+	return &editor{in: bufio.NewReader(in), out: out}
+}
+
+type lineState struct {
+	prompt string
+	buf    []rune
+	pos    int // カーソル位置（文字単位）
+}
+
+func (e *editor) ReadLine(prompt string) (string, error) {
+	s := &lineState{prompt: prompt}
+
+	hidx := len(e.history) // 参照中の履歴の位置（len(e.history)は入力中の行）
+	var saved []rune       // 履歴の参照を始める前に入力していた行
+
+	setLine := func(line []rune) {
+		s.buf = append([]rune{}, line...)
+		s.pos = len(s.buf)
+	}
+
+	e.refresh(s)
+
+	for {
+		key, err := e.readKey()
+		if err != nil {
+			return "", err
+		}
+
+		switch key {
+		case '\r', '\n':
+			io.WriteString(e.out, "\r\n")
+			return string(s.buf), nil
+
+		case ctrl('C'):
+			io.WriteString(e.out, "^C\r\n")
+			return "", errInterrupted
+
+		case ctrl('D'):
+			if len(s.buf) == 0 {
+				io.WriteString(e.out, "\r\n")
+				return "", io.EOF
+			}
+			s.delete()
+
+		case keyDelete:
+			s.delete()
+		case 127, ctrl('H'):
+			s.backspace()
+
+		case keyHome, ctrl('A'):
+			s.pos = 0
+		case keyEnd, ctrl('E'):
+			s.pos = len(s.buf)
+		case keyLeft, ctrl('B'):
+			s.left()
+		case keyRight, ctrl('F'):
+			s.right()
+
+		case ctrl('K'):
+			s.buf = s.buf[:s.pos]
+		case ctrl('U'):
+			s.buf = s.buf[s.pos:]
+			s.pos = 0
+		case ctrl('W'):
+			s.deleteWord()
+
+		case ctrl('L'):
+			io.WriteString(e.out, "\x1b[H\x1b[2J")
+
+		case keyUp, ctrl('P'):
+			if hidx > 0 {
+				if hidx == len(e.history) {
+					saved = append([]rune{}, s.buf...)
+				}
+				hidx -= 1
+				setLine([]rune(e.history[hidx]))
+			}
+		case keyDown, ctrl('N'):
+			if hidx < len(e.history) {
+				hidx += 1
+				if hidx == len(e.history) {
+					setLine(saved)
+				} else {
+					setLine([]rune(e.history[hidx]))
+				}
+			}
+
+		default:
+			if unicode.IsPrint(key) {
+				s.insert(key)
+			}
+		}
+
+		e.refresh(s)
+	}
+}
+
+func ctrl(c rune) rune {
+	return c & 0x1f
+}
+
+// エスケープシーケンスで入力される特殊キー（Unicodeの範囲外の値を割り当てる）
+const (
+	keyUp rune = unicode.MaxRune + 1 + iota
+	keyDown
+	keyRight
+	keyLeft
+	keyHome
+	keyEnd
+	keyDelete
+	keyUnknown
+)
+
+// 1文字または1つの特殊キーを読み込む
+func (e *editor) readKey() (rune, error) {
+	r, _, err := e.in.ReadRune()
+	if err != nil || r != 0x1b {
+		return r, err
+	}
+
+	// ESC [ x または ESC O x
+	r, _, err = e.in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if r != '[' && r != 'O' {
+		return keyUnknown, nil
+	}
+
+	r, _, err = e.in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+
+	switch r {
+	case 'A':
+		return keyUp, nil
+	case 'B':
+		return keyDown, nil
+	case 'C':
+		return keyRight, nil
+	case 'D':
+		return keyLeft, nil
+	case 'H':
+		return keyHome, nil
+	case 'F':
+		return keyEnd, nil
+	}
+
+	if r < '0' || '9' < r {
+		return keyUnknown, nil
+	}
+
+	// ESC [ n ~
+	n := r
+	for r != '~' {
+		if r, _, err = e.in.ReadRune(); err != nil {
+			return 0, err
+		}
+	}
+
+	switch n {
+	case '1', '7':
+		return keyHome, nil
+	case '4', '8':
+		return keyEnd, nil
+	case '3':
+		return keyDelete, nil
+	}
+
+	return keyUnknown, nil
+}
+
+// 行を再描画してカーソルを移動する
+func (e *editor) refresh(s *lineState) {
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", s.prompt, string(s.buf))
+	if n := stringWidth(s.buf[s.pos:]); n > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", n)
+	}
+}
+
+func (s *lineState) insert(r rune) {
+	s.buf = append(s.buf, 0)
+	copy(s.buf[s.pos+1:], s.buf[s.pos:])
+	s.buf[s.pos] = r
+	s.pos += 1
+}
+
+func (s *lineState) backspace() {
+	if s.pos > 0 {
+		s.buf = append(s.buf[:s.pos-1], s.buf[s.pos:]...)
+		s.pos -= 1
+	}
+}
+
+func (s *lineState) delete() {
+	if s.pos < len(s.buf) {
+		s.buf = append(s.buf[:s.pos], s.buf[s.pos+1:]...)
+	}
+}
+
+func (s *lineState) deleteWord() {
+	start := s.pos
+	for start > 0 && s.buf[start-1] == ' ' {
+		start -= 1
+	}
+	for start > 0 && s.buf[start-1] != ' ' {
+		start -= 1
+	}
+	s.buf = append(s.buf[:start], s.buf[s.pos:]...)
+	s.pos = start
+}
+
+func (s *lineState) left() {
+	if s.pos > 0 {
+		s.pos -= 1
+	}
+}
+
+func (s *lineState) right() {
+	if s.pos < len(s.buf) {
+		s.pos += 1
+	}
+}
+
+// 端末上での表示幅（全角文字は2桁）
+func stringWidth(rs []rune) int {
+	w := 0
+	for _, r := range rs {
+		w += width.Rune(r)
+	}
+	return w
+}
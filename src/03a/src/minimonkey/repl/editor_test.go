@@ -0,0 +1,78 @@
+package repl
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestEditorReadLine(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 1\r", "let x = 1"},
+		{"ac\x1b[Db\r", "abc"},       // ←で戻って挿入
+		{"ac\x02b\x06d\r", "abcd"},   // Ctrl-B, Ctrl-F
+		{"abc\x7f\x7fd\r", "ad"},     // Backspace
+		{"abc\x01\x1b[3~\r", "bc"},   // Home, Delete
+		{"abc\x01x\x05y\r", "xabcy"}, // Ctrl-A, Ctrl-E
+		{"abc def\x17\r", "abc "},    // Ctrl-W
+		{"abcdef\x1b[D\x1b[D\x0b\r", "abcd"},
+		{"abcdef\x1b[D\x1b[D\x15\r", "ef"},
+		{"ab\x01\x04\r", "b"}, // 空行でなければCtrl-Dは1文字削除
+	}
+
+	for _, tt := range tests {
+		e := newEditor(strings.NewReader(tt.input), io.Discard)
+
+		line, err := e.ReadLine(PROMPT)
+		if err != nil {
+			t.Fatalf("ReadLine(%q) returned error: %s", tt.input, err)
+		}
+		if line != tt.expected {
+			t.Errorf("ReadLine(%q) got %q, expected %q", tt.input, line, tt.expected)
+		}
+	}
+}
+
+func TestEditorHistory(t *testing.T) {
+	input := "\x1b[A\r" + // 直前の行
+		"\x1b[A\x1b[A\r" + // 2つ前の行
+		"new\x10\x0e\r" + // Ctrl-P, Ctrl-Nで入力中の行に戻る
+		"\x1b[A\x1b[A\x1b[A\x1b[B\r" // 先頭より前には戻らない
+
+	e := newEditor(strings.NewReader(input), io.Discard)
+	e.history = []string{"first", "second"}
+
+	for _, expected := range []string{"second", "first", "new", "second"} {
+		line, err := e.ReadLine(PROMPT)
+		if err != nil {
+			t.Fatalf("ReadLine returned error: %s", err)
+		}
+		if line != expected {
+			t.Errorf("ReadLine got %q, expected %q", line, expected)
+		}
+	}
+}
+
+func TestEditorInterrupt(t *testing.T) {
+	var out bytes.Buffer
+	e := newEditor(strings.NewReader("abc\x03"), &out)
+
+	if _, err := e.ReadLine(PROMPT); err != errInterrupted {
+		t.Errorf("ReadLine got error %v, expected errInterrupted", err)
+	}
+	if !strings.HasSuffix(out.String(), "^C\r\n") {
+		t.Errorf("output got %q, expected to end with %q", out.String(), "^C\r\n")
+	}
+}
+
+func TestEditorEOF(t *testing.T) {
+	e := newEditor(strings.NewReader("\x04"), io.Discard)
+
+	if _, err := e.ReadLine(PROMPT); err != io.EOF {
+		t.Errorf("ReadLine got error %v, expected io.EOF", err)
+	}
+}
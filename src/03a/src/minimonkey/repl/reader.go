@@ -0,0 +1,141 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Ctrl-Cで入力中の行が破棄された
+var errInterrupted = errors.New("interrupted")
+
+// 履歴ファイルに保存する最大の行数
+const HISTORY_SIZE = 1000
+
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+	AddHistory(line string)
+}
+
+// 端末であれば行編集と履歴を有効にする
+func newLineReader(in io.Reader, out io.Writer) lineReader {
+	fin, ok := in.(*os.File)
+	if !ok || !isTerminal(fin.Fd()) {
+		return newPlainReader(in, out)
+	}
+	if fout, ok := out.(*os.File); !ok || !isTerminal(fout.Fd()) {
+		return newPlainReader(in, out)
+	}
+
+	r := &terminalReader{
+		fd:          fin.Fd(),
+		editor:      newEditor(fin, out),
+		historyFile: historyFile(),
+	}
+	r.editor.history = loadHistory(r.historyFile)
+
+	return r
+}
+
+type plainReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func newPlainReader(in io.Reader, out io.Writer) *plainReader {
+	return &plainReader{scanner: bufio.NewScanner(in), out: out}
+}
+
+func (r *plainReader) ReadLine(prompt string) (string, error) {
+	fmt.Fprint(r.out, prompt)
+
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+
+	return r.scanner.Text(), nil
+}
+
+func (r *plainReader) AddHistory(line string) {}
+
+type terminalReader struct {
+	fd          uintptr
+	editor      *editor
+	historyFile string
+}
+
+// 行の編集中のみ端末をrawモードにする
+func (r *terminalReader) ReadLine(prompt string) (string, error) {
+	state, err := makeRaw(r.fd)
+	if err != nil {
+		return "", err
+	}
+	defer restore(r.fd, state)
+
+	return r.editor.ReadLine(prompt)
+}
+
+func (r *terminalReader) AddHistory(line string) {
+	h := r.editor.history
+	if len(h) > 0 && h[len(h)-1] == line {
+		return
+	}
+	r.editor.history = append(h, line)
+
+	if r.historyFile == "" {
+		return
+	}
+
+	f, err := os.OpenFile(r.historyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	fmt.Fprintln(f, line)
+}
+
+// $HOME/.minimonkey_history
+func historyFile() string {
+	home := os.Getenv("HOME")
+	if home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".minimonkey_history")
+}
+
+func loadHistory(filename string) []string {
+	if filename == "" {
+		return nil
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var history []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.TrimSpace(line) != "" {
+			history = append(history, line)
+		}
+	}
+
+	// 古い履歴を捨ててファイルを書き直す
+	if len(history) > HISTORY_SIZE {
+		history = history[len(history)-HISTORY_SIZE:]
+		ioutil.WriteFile(filename, []byte(strings.Join(history, "\n")+"\n"), 0600)
+	}
+
+	return history
+}
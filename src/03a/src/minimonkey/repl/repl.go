@@ -1,52 +1,163 @@
 package repl
 
 import (
-	"bufio"
+	"context"
 	"fmt"
 	"io"
+	"strings"
 
-	"minimonkey/evalutor"
+	"minimonkey/ast"
+	"minimonkey/interpreter"
 	"minimonkey/lexer"
-	"minimonkey/object"
 	"minimonkey/parser"
+	"minimonkey/token"
 )
 
 const PROMPT = ">> "
 
-func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
-	env := object.NewEnvironment()
+// 入力が続く場合のプロンプト
+const CONTINUATION_PROMPT = ".. "
+
+// engineで指定した方式で入力を評価する
+func Start(in io.Reader, out io.Writer, engine interpreter.Engine) {
+	reader := newLineReader(in, out)
+	s := &session{engine: engine, out: out}
+	s.reset()
+
+	var lines []string
 
 	for {
-		fmt.Fprint(out, PROMPT)
-		scanned := scanner.Scan()
-		if !scanned {
+		prompt := PROMPT
+		if len(lines) > 0 {
+			prompt = CONTINUATION_PROMPT
+		}
+
+		line, err := reader.ReadLine(prompt)
+		if err == errInterrupted {
+			lines = nil
+			continue
+		}
+		if err != nil {
 			return
 		}
 
-		line := scanner.Text()
+		if strings.TrimSpace(line) != "" {
+			reader.AddHistory(line)
+		}
 
-		l := lexer.New(line)
-		p := parser.New(l)
+		if len(lines) == 0 && strings.HasPrefix(strings.TrimSpace(line), ":") {
+			s.command(strings.TrimSpace(line))
+			continue
+		}
 
-		program := p.Parse()
+		lines = append(lines, line)
+		src := strings.Join(lines, "\n")
 
-		if len(p.Errors()) != 0 {
-			printErrors(out, p.Errors())
+		// 継続中の空行は入力の終わりとみなす
+		if strings.TrimSpace(line) != "" && isIncomplete(src) {
 			continue
 		}
+		lines = nil
 
-		evaluted := evalutor.Eval(program, env)
-
-		if evaluted != nil {
-			io.WriteString(out, evaluted.Inspect())
-			io.WriteString(out, "\n")
+		if strings.TrimSpace(src) == "" {
+			continue
 		}
+
+		s.eval("", src)
 	}
 }
 
-func printErrors(out io.Writer, errors []error) {
-	for _, err := range errors {
-		io.WriteString(out, "ERROR: "+err.Error()+"\n")
+// REPLの実行中の状態（:resetでインタプリタを作り直す）
+type session struct {
+	engine interpreter.Engine
+	in     *interpreter.Interpreter
+	out    io.Writer
+}
+
+func (s *session) reset() {
+	s.in = interpreter.NewWithEngine(s.engine)
+	s.in.SetOutput(s.out)
+}
+
+// 構文解析エラーがあれば表示してnilを返す
+func (s *session) parse(filename string, src string) *ast.Program {
+	p := parser.New(lexer.NewFile(filename, src))
+	program := p.Parse()
+
+	if len(p.Errors()) != 0 {
+		io.WriteString(s.out, parser.RenderErrors(src, p.Errors()))
+		return nil
 	}
+
+	return program
+}
+
+// 評価して結果を表示する
+func (s *session) eval(filename string, src string) {
+	program := s.parse(filename, src)
+	if program == nil {
+		return
+	}
+
+	// コメントのみの入力は何も表示しない
+	if len(program.Statements) == 0 {
+		return
+	}
+
+	evaluted, err := s.in.EvalFile(context.Background(), filename, src)
+	switch err := err.(type) {
+	case nil:
+		io.WriteString(s.out, evaluted.Inspect())
+		io.WriteString(s.out, "\n")
+	case *interpreter.RuntimeError:
+		io.WriteString(s.out, err.Object.Inspect())
+		io.WriteString(s.out, "\n")
+	default:
+		fmt.Fprintln(s.out, "ERROR: "+err.Error())
+	}
+}
+
+// 改行または入力の終わりにより自動挿入されたセミコロン
+func isAutoSemicolon(tok token.Token) bool {
+	return tok.Type == token.SEMICOLON && (tok.Pos.Line != tok.End.Line || tok.Pos == tok.End)
+}
+
+// 行末で入力が途切れているか（括弧が閉じていない、または演算子で終わっている）
+func isIncomplete(src string) bool {
+	l := lexer.New(src)
+	depth := 0
+	var last token.Token
+
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case token.LPAREN, token.LBRACE, token.LBRACKET:
+			depth += 1
+		case token.RPAREN, token.RBRACE, token.RBRACKET:
+			depth -= 1
+		case token.SEMICOLON:
+			// 自動挿入されたセミコロンは無視する
+			if isAutoSemicolon(tok) {
+				continue
+			}
+		case token.ILLEGAL:
+			if tok.Literal == lexer.UnterminatedComment {
+				return true
+			}
+		}
+		last = tok
+	}
+
+	if depth > 0 {
+		return true
+	}
+
+	switch last.Type {
+	case token.ASSIGN, token.PLUS, token.MINUS, token.ASTERISK, token.SLASH, token.PERCENT, token.BANG,
+		token.AMPERSAND, token.PIPE, token.CARET, token.TILDE, token.SHL, token.SHR, token.DOTDOT, token.DOTDOT_LT,
+		token.EQ, token.NOT_EQ, token.LT, token.GT, token.LT_EQ, token.GT_EQ,
+		token.COMMA, token.COLON:
+		return true
+	}
+
+	return false
 }
@@ -0,0 +1,123 @@
+package repl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"minimonkey/interpreter"
+)
+
+func TestIsIncomplete(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"1 + 2", false},
+		{"1 +", true},
+		{"let x =", true},
+		{"let f = fn(x) {", true},
+		{"let f = fn(x) {\n  x * 2\n}", false},
+		{"[1, 2,", true},
+		{"{\"a\":", true},
+		{"add(1,\n2", true},
+		{"add(1,\n2)", false},
+		{"if (true) { 1 }", false},
+		{"\"{\"", false},
+		{"1 /* comment", true},
+		{"1 /* comment */", false},
+		{"add(1, // first\n", true},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := isIncomplete(tt.input); got != tt.expected {
+			t.Errorf("isIncomplete(%q) got %t, expected %t", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestStart(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 + 2\n", ">> 3\n>> "},
+		{
+			"let double = fn(x) {\nx * 2\n}\ndouble(21)\n",
+			">> .. .. fn(x){ (x * 2); }\n>> 42\n>> ",
+		},
+		{"[1,\n2]\n", ">> .. [1, 2]\n>> "},
+		{"puts(\"hi\")\n", ">> hi\nnull\n>> "},
+		// 継続中の空行で入力を打ち切る
+		{"(1 +\n\n2\n", ">> .. 2:1: no prefix parse function for EOF found\n    \n    ^\n    hint: an expression is missing here\n>> 2\n>> "},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		Start(strings.NewReader(tt.input), &out, interpreter.EVAL)
+
+		if out.String() != tt.expected {
+			t.Errorf("Start(%q) got %q, expected %q", tt.input, out.String(), tt.expected)
+		}
+	}
+}
+
+// 仮想マシンでも評価をまたいで束縛を保持する
+func TestStartVM(t *testing.T) {
+	input := "let x = 1\nx + 1\nputs(x)\n:env\n:reset\nx\n"
+	expected := ">> 1\n>> 2\n>> 1\nnull\n>> x = 1\n>> >> ERROR: 1:1: identifier not found: x\n>> "
+
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out, interpreter.VM)
+
+	if out.String() != expected {
+		t.Errorf("Start(%q) got %q, expected %q", input, out.String(), expected)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			":tokens f()\n",
+			">> 1:1    IDENT      \"f\"\n1:2    (          \"(\"\n1:3    )          \")\"\n1:4    ;          \";\" (inserted)\n1:4    EOF        \"\"\n>> ",
+		},
+		{
+			":ast -x\n",
+			">> Program 1:1-1:3\n  Statements[0]: ExpressionStatement 1:1-1:3\n    Expression: PrefixExpression 1:1-1:3 Operator=\"-\"\n      Right: Identifier 1:2-1:3 Value=\"x\"\n>> ",
+		},
+		{
+			":ast 1.5\n",
+			">> Program 1:1-1:4\n  Statements[0]: ExpressionStatement 1:1-1:4\n    Expression: FloatLiteral 1:1-1:4 Value=1.5\n>> ",
+		},
+		{
+			":ast 99999999999999999999\n",
+			">> Program 1:1-1:21\n  Statements[0]: ExpressionStatement 1:1-1:21\n    Expression: IntegerLiteral 1:1-1:21 Value=0 Big=99999999999999999999\n>> ",
+		},
+		{"let b = 2\nlet a = 1\n:env\n", ">> 2\n>> 1\n>> a = 1\nb = 2\n>> "},
+		{"let a = 1\n:reset\n:env\na\n", ">> 1\n>> >> >> ERROR: 1:1: identifier not found: a\n>> "},
+		{":load\n", ">> usage: :load <file>\n>> "},
+		{":foo\n", ">> unknown command :foo; type :help for a list of commands\n>> "},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		Start(strings.NewReader(tt.input), &out, interpreter.EVAL)
+
+		if out.String() != tt.expected {
+			t.Errorf("Start(%q) got %q, expected %q", tt.input, out.String(), tt.expected)
+		}
+	}
+}
+
+func TestTimeCommand(t *testing.T) {
+	var out bytes.Buffer
+	Start(strings.NewReader(":time 1 + 2\n"), &out, interpreter.EVAL)
+
+	if !strings.HasPrefix(out.String(), ">> 3\nelapsed: ") {
+		t.Errorf("Start got %q, expected to start with %q", out.String(), ">> 3\nelapsed: ")
+	}
+}
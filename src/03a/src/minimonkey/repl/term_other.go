@@ -0,0 +1,22 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package repl
+
+import "errors"
+
+// 行編集に対応していない環境では常に行単位で読み込む
+
+type termState struct{}
+
+func isTerminal(fd uintptr) bool {
+	return false
+}
+
+func makeRaw(fd uintptr) (*termState, error) {
+	return nil, errors.New("raw mode is not supported")
+}
+
+func restore(fd uintptr, state *termState) error {
+	return nil
+}
@@ -1,29 +1,70 @@
 package token
 
+import "fmt"
+
 const (
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
 
-	IDENT = "IDENT"
-	INT   = "INT"
+	IDENT  = "IDENT"
+	INT    = "INT"
+	FLOAT  = "FLOAT"
+	STRING = "STRING"
+
+	ASSIGN          = "="
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	SLASH_ASSIGN    = "/="
 
-	ASSIGN   = "="
 	PLUS     = "+"
 	MINUS    = "-"
 	ASTERISK = "*"
 	SLASH    = "/"
+	PERCENT  = "%"
+	BANG     = "!"
+
+	AMPERSAND = "&"
+	PIPE      = "|"
+	CARET     = "^"
+	TILDE     = "~"
+	SHL       = "<<"
+	SHR       = ">>"
+	DOTDOT    = ".."  // 終端を含む範囲
+	DOTDOT_LT = "..<" // 終端を含まない範囲
+
+	EQ     = "=="
+	NOT_EQ = "!="
+	LT     = "<"
+	GT     = ">"
+	LT_EQ  = "<="
+	GT_EQ  = ">="
 
 	COMMA     = ","
 	SEMICOLON = ";"
+	COLON     = ":"
+	ELLIPSIS  = "..." // 可変長引数と引数の展開
 
-	LPAREN = "("
-	RPAREN = ")"
-	LBRACE = "{"
-	RBRACE = "}"
+	LPAREN   = "("
+	RPAREN   = ")"
+	LBRACE   = "{"
+	RBRACE   = "}"
+	LBRACKET = "["
+	RBRACKET = "]"
 
 	LET      = "LET"
+	CONST    = "CONST"
 	FUNCTION = "FUNCTION"
 	RETURN   = "RETURN"
+	TRUE     = "TRUE"
+	FALSE    = "FALSE"
+	IF       = "IF"
+	ELSE     = "ELSE"
+	WHILE    = "WHILE"
+	FOR      = "FOR"
+	IN       = "IN"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
 )
 
 type TokenType string
@@ -31,12 +72,57 @@ type TokenType string
 type Token struct {
 	Type    TokenType
 	Literal string
+	Pos     Pos // トークンの開始位置
+	End     Pos // トークンの直後の位置
+}
+
+// コメント（Textは`//`または`/* */`を含む）
+type Comment struct {
+	Text string
+	Pos  Pos // コメントの開始位置
+	End  Pos // コメントの直後の位置
+}
+
+// ソースコード上の位置
+type Pos struct {
+	Filename string
+	Offset   int // バイトオフセット（0始まり）
+	Line     int // 行番号（1始まり）
+	Column   int // 列番号（1始まり）
+}
+
+func (p Pos) IsValid() bool {
+	return p.Line > 0
+}
+
+// file:line:col（ファイル名がない場合はline:col）
+func (p Pos) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+
+	s := fmt.Sprintf("%d:%d", p.Line, p.Column)
+	if p.Filename != "" {
+		s = p.Filename + ":" + s
+	}
+
+	return s
 }
 
 var keywords = map[string]TokenType{
-	"let":    LET,
-	"fn":     FUNCTION,
-	"return": RETURN,
+	"let":      LET,
+	"const":    CONST,
+	"fn":       FUNCTION,
+	"return":   RETURN,
+	"true":     TRUE,
+	"false":    FALSE,
+	"if":       IF,
+	"else":     ELSE,
+	"while":    WHILE,
+	"for":      FOR,
+	"in":       IN,
+	"break":    BREAK,
+	"continue": CONTINUE,
 }
 
 func LookupIdent(ident string) TokenType {
@@ -0,0 +1,10 @@
+package vm
+
+import (
+	"minimonkey/enginetest"
+	"testing"
+)
+
+func TestEngine(t *testing.T) {
+	enginetest.Run(t, testEval)
+}
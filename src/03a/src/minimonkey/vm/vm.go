@@ -0,0 +1,817 @@
+// compilerが生成したバイトコードを実行するスタックマシン
+package vm
+
+import (
+	"context"
+	"fmt"
+
+	"minimonkey/ast"
+	"minimonkey/code"
+	"minimonkey/compiler"
+	"minimonkey/evalutor"
+	"minimonkey/object"
+)
+
+// スタックと呼び出しの深さの上限（スタックは必要に応じて拡張する）
+const STACK_SIZE = 1 << 20
+const MAX_FRAMES = 1 << 16
+
+const GLOBALS_SIZE = 1 << 16
+
+// 評価器と同じシングルトンを使用する（組み込み関数の戻り値と比較できるようにする）
+var (
+	NULL  = evalutor.NULL
+	TRUE  = evalutor.TRUE
+	FALSE = evalutor.FALSE
+)
+
+type VM struct {
+	constants []object.Object
+
+	stack []object.Object
+	sp    int // 次に積む位置（スタックの先頭はstack[sp-1]）
+
+	globals     []object.Object
+	globalNames []string
+
+	frames []*Frame
+
+	result object.Object
+	done   <-chan struct{} // 閉じられると実行を中断する
+	ctx    context.Context
+}
+
+func New(bytecode *compiler.Bytecode) *VM {
+	return NewWithGlobals(bytecode, make([]object.Object, GLOBALS_SIZE))
+}
+
+// 以前の実行で設定したグローバル変数を引き継ぐ
+func NewWithGlobals(bytecode *compiler.Bytecode, globals []object.Object) *VM {
+	mainFn := &object.CompiledFunction{
+		Instructions: bytecode.Instructions,
+		Positions:    bytecode.Positions,
+		NumLocals:    len(bytecode.Locals),
+		LocalNames:   bytecode.Locals,
+	}
+	mainClosure := &object.Closure{Fn: mainFn}
+
+	return &VM{
+		constants:   bytecode.Constants,
+		stack:       make([]object.Object, 1024),
+		sp:          mainFn.NumLocals,
+		globals:     globals,
+		globalNames: bytecode.Globals,
+		frames:      []*Frame{NewFrame(mainClosure, 0)},
+	}
+}
+
+// プログラムの結果（最後に評価した文の値）
+func (vm *VM) Result() object.Object {
+	return vm.result
+}
+
+func (vm *VM) Run() error {
+	return vm.RunContext(context.Background())
+}
+
+// ctxがキャンセルされると関数呼び出しまたはループの繰り返しの時点で実行を中断する。
+// 評価時のエラーは発生した位置を含む*object.Errorとして返す。
+func (vm *VM) RunContext(ctx context.Context) (err error) {
+	// 想定外のGoのパニックで処理系全体を停止させない
+	defer func() {
+		if r := recover(); r != nil {
+			err = vm.panicToError(r)
+		}
+	}()
+
+	vm.ctx = ctx
+	vm.done = ctx.Done()
+
+	if err := vm.run(0); err != errHalt {
+		return err
+	}
+	return nil
+}
+
+// フレームの数がdepthになるまで命令を実行する
+func (vm *VM) run(depth int) error {
+	for len(vm.frames) > depth {
+		frame := vm.currentFrame()
+		frame.ip += 1
+
+		ip := frame.ip
+		ins := frame.Instructions()
+
+		if err := vm.execute(frame, ins, ip); err != nil {
+			if errObj, ok := err.(*object.Error); ok && !errObj.Pos.IsValid() {
+				errObj.Pos = frame.cl.Fn.Positions.Lookup(ip)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 組み込み関数から関数を呼び出す（object.Caller）。
+// クロージャは新しいフレームで実行し、そのフレームから戻るまで命令を実行する。
+func (vm *VM) call(fn object.Object, args ...object.Object) object.Object {
+	depth := len(vm.frames)
+
+	if err := vm.push(fn); err != nil {
+		return toErrorObject(err)
+	}
+	for _, arg := range args {
+		if err := vm.push(arg); err != nil {
+			return toErrorObject(err)
+		}
+	}
+
+	if err := vm.executeCall(len(args)); err != nil {
+		return toErrorObject(err)
+	}
+	if err := vm.run(depth); err != nil {
+		return toErrorObject(err)
+	}
+
+	return vm.pop()
+}
+
+func toErrorObject(err error) *object.Error {
+	if errObj, ok := err.(*object.Error); ok {
+		return errObj
+	}
+	return &object.Error{Message: err.Error()}
+}
+
+// メインのプログラムが終了した
+var errHalt = fmt.Errorf("halt")
+
+// 組み込み関数で発生したGoのパニック。呼び出した組み込み関数の名前を記録する。
+type builtinPanic struct {
+	value interface{}
+	name  string
+}
+
+// パニックを実行中の命令の位置のエラーに変換し、呼び出し中の関数をスタックトレースとする
+func (vm *VM) panicToError(r interface{}) *object.Error {
+	callee := ""
+	if p, ok := r.(*builtinPanic); ok {
+		r, callee = p.value, p.name
+	}
+
+	err := newError("internal error: %v", r)
+
+	for i := len(vm.frames) - 1; i >= 0; i-- {
+		f := vm.frames[i]
+		pos := f.cl.Fn.Positions.Lookup(f.ip)
+
+		if i == len(vm.frames)-1 {
+			err.Pos = pos
+		}
+		if callee != "" {
+			err.Stack = append(err.Stack, object.StackFrame{Function: callee, Pos: pos})
+		}
+
+		callee = "fn"
+		if lit := f.cl.Fn.Literal; lit != nil && lit.Name != "" {
+			callee = lit.Name
+		}
+	}
+
+	return err
+}
+
+func (vm *VM) execute(frame *Frame, ins code.Instructions, ip int) error {
+	op := code.Opcode(ins[ip])
+
+	switch op {
+	case code.OpConstant:
+		idx := code.ReadUint16(ins[ip+1:])
+		frame.ip += 2
+		return vm.push(vm.constants[idx])
+
+	case code.OpPop:
+		vm.pop()
+
+	case code.OpAdd, code.OpSub, code.OpMul, code.OpDiv, code.OpMod,
+		code.OpBitAnd, code.OpBitOr, code.OpBitXor, code.OpShiftLeft, code.OpShiftRight, code.OpRange, code.OpRangeExclusive,
+		code.OpEqual, code.OpNotEqual, code.OpLessThan, code.OpGreaterThan, code.OpLessEqual, code.OpGreaterEqual:
+		return vm.executeBinaryOperation(op)
+
+	case code.OpMinus:
+		operand := vm.pop()
+		switch operand.Type() {
+		case object.INTEGER_OBJ:
+			return vm.push(object.NegateInteger(operand))
+		case object.FLOAT_OBJ:
+			return vm.push(&object.Float{Value: -operand.(*object.Float).Value})
+		default:
+			return newError("unknown operator -%s", operand.Type())
+		}
+
+	case code.OpBang:
+		return vm.push(nativeBoolToBooleanObject(!isTruthy(vm.pop())))
+
+	case code.OpBitNot:
+		operand := vm.pop()
+		if operand.Type() != object.INTEGER_OBJ {
+			return newError("unknown operator ~%s", operand.Type())
+		}
+		return vm.push(object.NotInteger(operand))
+
+	case code.OpTrue:
+		return vm.push(TRUE)
+	case code.OpFalse:
+		return vm.push(FALSE)
+	case code.OpNull:
+		return vm.push(NULL)
+
+	case code.OpJump:
+		pos := int(code.ReadUint16(ins[ip+1:]))
+		frame.ip = pos - 1
+
+		// ループの先頭に戻るときに中断を確認する
+		if pos <= ip {
+			return vm.checkAborted()
+		}
+
+	case code.OpJumpNotTruthy:
+		pos := int(code.ReadUint16(ins[ip+1:]))
+		frame.ip += 2
+		if !isTruthy(vm.pop()) {
+			frame.ip = pos - 1
+		}
+
+	case code.OpSetGlobal:
+		idx := code.ReadUint16(ins[ip+1:])
+		frame.ip += 2
+		vm.globals[idx] = vm.pop()
+
+	case code.OpGetGlobal:
+		idx := code.ReadUint16(ins[ip+1:])
+		frame.ip += 2
+		val := vm.globals[idx]
+		if val == nil {
+			return newError("identifier not found: %s", vm.globalNames[idx])
+		}
+		return vm.push(val)
+
+	case code.OpSetLocal:
+		idx := code.ReadUint8(ins[ip+1:])
+		frame.ip += 1
+		vm.stack[frame.basePointer+int(idx)] = vm.pop()
+
+	case code.OpGetLocal:
+		idx := code.ReadUint8(ins[ip+1:])
+		frame.ip += 1
+		val := vm.stack[frame.basePointer+int(idx)]
+		if val == nil {
+			return newError("identifier not found: %s", frame.cl.Fn.LocalNames[idx])
+		}
+		return vm.push(val)
+
+	case code.OpGetFree:
+		idx := code.ReadUint8(ins[ip+1:])
+		frame.ip += 1
+		val := frame.cl.Free[idx]
+		if val == nil {
+			return newError("identifier not found: %s", frame.cl.Fn.FreeNames[idx])
+		}
+		return vm.push(val)
+
+	case code.OpCurrentClosure:
+		return vm.push(frame.cl)
+
+	case code.OpAssignGlobal:
+		idx := code.ReadUint16(ins[ip+1:])
+		frame.ip += 2
+		if vm.globals[idx] == nil {
+			return newError("assignment to undefined variable: %s", vm.globalNames[idx])
+		}
+		vm.globals[idx] = vm.pop()
+
+	case code.OpMakeCell:
+		idx := code.ReadUint8(ins[ip+1:])
+		frame.ip += 1
+		slot := &vm.stack[frame.basePointer+int(idx)]
+		if _, ok := (*slot).(*object.Cell); !ok {
+			*slot = &object.Cell{Value: *slot}
+		}
+
+	case code.OpNewCell:
+		idx := code.ReadUint8(ins[ip+1:])
+		frame.ip += 1
+		vm.stack[frame.basePointer+int(idx)] = &object.Cell{}
+
+	case code.OpGetLocalCell:
+		idx := code.ReadUint8(ins[ip+1:])
+		frame.ip += 1
+		cell, ok := vm.stack[frame.basePointer+int(idx)].(*object.Cell)
+		if !ok || cell.Value == nil {
+			return newError("identifier not found: %s", frame.cl.Fn.LocalNames[idx])
+		}
+		return vm.push(cell.Value)
+
+	case code.OpSetLocalCell:
+		idx := code.ReadUint8(ins[ip+1:])
+		frame.ip += 1
+		cell, ok := vm.stack[frame.basePointer+int(idx)].(*object.Cell)
+		if !ok {
+			return newError("assignment to undefined variable: %s", frame.cl.Fn.LocalNames[idx])
+		}
+		cell.Value = vm.pop()
+
+	case code.OpGetFreeCell:
+		idx := code.ReadUint8(ins[ip+1:])
+		frame.ip += 1
+		cell := frame.cl.Free[idx].(*object.Cell)
+		if cell.Value == nil {
+			return newError("identifier not found: %s", frame.cl.Fn.FreeNames[idx])
+		}
+		return vm.push(cell.Value)
+
+	case code.OpSetFreeCell:
+		idx := code.ReadUint8(ins[ip+1:])
+		frame.ip += 1
+		frame.cl.Free[idx].(*object.Cell).Value = vm.pop()
+
+	case code.OpArray:
+		n := int(code.ReadUint16(ins[ip+1:]))
+		frame.ip += 2
+
+		elements := make([]object.Object, n)
+		copy(elements, vm.stack[vm.sp-n:vm.sp])
+		vm.sp -= n
+
+		return vm.push(&object.Array{Elements: elements})
+
+	case code.OpHash:
+		n := int(code.ReadUint16(ins[ip+1:]))
+		frame.ip += 2
+
+		hash, err := vm.buildHash(vm.sp-n, vm.sp)
+		if err != nil {
+			return err
+		}
+		vm.sp -= n
+
+		return vm.push(hash)
+
+	case code.OpIndex:
+		index := vm.pop()
+		left := vm.pop()
+		return vm.executeIndexExpression(left, index)
+
+	case code.OpIter:
+		it, err := object.Iterate(vm.pop())
+		if err != nil {
+			return err
+		}
+		return vm.push(it)
+
+	case code.OpIterNext:
+		pos := int(code.ReadUint16(ins[ip+1:]))
+		frame.ip += 2
+
+		val, ok := vm.stack[vm.sp-1].(object.Iterator).Next()
+		if !ok {
+			frame.ip = pos - 1
+			return nil
+		}
+		if errObj, ok := val.(*object.Error); ok {
+			return errObj
+		}
+		return vm.push(val)
+
+	case code.OpCall:
+		numArgs := int(code.ReadUint8(ins[ip+1:]))
+		frame.ip += 1
+		return vm.executeCall(numArgs)
+
+	case code.OpReturnValue:
+		returnValue := vm.pop()
+
+		if len(vm.frames) == 1 {
+			vm.result = returnValue
+			return errHalt
+		}
+
+		frame := vm.popFrame()
+		vm.sp = frame.basePointer - 1
+
+		return vm.push(returnValue)
+
+	case code.OpClosure:
+		idx := code.ReadUint16(ins[ip+1:])
+		numFree := int(code.ReadUint8(ins[ip+3:]))
+		frame.ip += 3
+		return vm.pushClosure(int(idx), numFree)
+
+	case code.OpSpread:
+		return vm.push(&object.Spread{Value: vm.pop()})
+
+	case code.OpKeyword:
+		idx := code.ReadUint16(ins[ip+1:])
+		frame.ip += 2
+		name := vm.constants[idx].(*object.String).Value
+		return vm.push(&object.Keyword{Name: name, Value: vm.pop()})
+
+	case code.OpJumpIfBound:
+		idx := code.ReadUint8(ins[ip+1:])
+		pos := int(code.ReadUint16(ins[ip+2:]))
+		frame.ip += 3
+		if vm.stack[frame.basePointer+int(idx)] != nil {
+			frame.ip = pos - 1
+		}
+
+	default:
+		return fmt.Errorf("unknown opcode %d", op)
+	}
+
+	return nil
+}
+
+func newError(format string, a ...interface{}) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...)}
+}
+
+// RunContextのctxがキャンセルされていればエラーを返す
+func (vm *VM) checkAborted() error {
+	select {
+	case <-vm.done:
+		return newError("evaluation aborted: %s", vm.ctx.Err())
+	default:
+		return nil
+	}
+}
+
+func (vm *VM) currentFrame() *Frame {
+	return vm.frames[len(vm.frames)-1]
+}
+
+func (vm *VM) pushFrame(f *Frame) error {
+	if len(vm.frames) >= MAX_FRAMES {
+		return newError("stack overflow")
+	}
+	vm.frames = append(vm.frames, f)
+	return nil
+}
+
+func (vm *VM) popFrame() *Frame {
+	f := vm.frames[len(vm.frames)-1]
+	vm.frames = vm.frames[:len(vm.frames)-1]
+	return f
+}
+
+// スタックにn個の値を積める領域を確保する
+func (vm *VM) reserve(n int) error {
+	if vm.sp+n <= len(vm.stack) {
+		return nil
+	}
+	if vm.sp+n > STACK_SIZE {
+		return newError("stack overflow")
+	}
+
+	size := len(vm.stack) * 2
+	for size < vm.sp+n {
+		size *= 2
+	}
+	if size > STACK_SIZE {
+		size = STACK_SIZE
+	}
+
+	stack := make([]object.Object, size)
+	copy(stack, vm.stack[:vm.sp])
+	vm.stack = stack
+
+	return nil
+}
+
+func (vm *VM) push(o object.Object) error {
+	if err := vm.reserve(1); err != nil {
+		return err
+	}
+
+	vm.stack[vm.sp] = o
+	vm.sp += 1
+
+	return nil
+}
+
+func (vm *VM) pop() object.Object {
+	o := vm.stack[vm.sp-1]
+	vm.sp -= 1
+	return o
+}
+
+var operators = map[code.Opcode]string{
+	code.OpAdd:            "+",
+	code.OpSub:            "-",
+	code.OpMul:            "*",
+	code.OpDiv:            "/",
+	code.OpMod:            "%",
+	code.OpBitAnd:         "&",
+	code.OpBitOr:          "|",
+	code.OpBitXor:         "^",
+	code.OpShiftLeft:      "<<",
+	code.OpShiftRight:     ">>",
+	code.OpRange:          "..",
+	code.OpRangeExclusive: "..<",
+	code.OpEqual:          "==",
+	code.OpNotEqual:       "!=",
+	code.OpLessThan:       "<",
+	code.OpGreaterThan:    ">",
+	code.OpLessEqual:      "<=",
+	code.OpGreaterEqual:   ">=",
+}
+
+func (vm *VM) executeBinaryOperation(op code.Opcode) error {
+	right := vm.pop()
+	left := vm.pop()
+
+	switch {
+	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
+		return vm.executeIntegerOperation(op, left, right)
+	case object.IsNumber(left) && object.IsNumber(right):
+		return vm.executeFloatOperation(op, left, right)
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return vm.executeStringOperation(op, left, right)
+	// 整数以外はシングルトン（TRUE, FALSE, NULL）または同一オブジェクトかどうかで比較する
+	case op == code.OpEqual:
+		return vm.push(nativeBoolToBooleanObject(left == right))
+	case op == code.OpNotEqual:
+		return vm.push(nativeBoolToBooleanObject(left != right))
+	default:
+		return newError("unknown operator %s %s %s", left.Type(), operators[op], right.Type())
+	}
+}
+
+// int64の範囲を超える結果はBigIntとなる
+func (vm *VM) executeIntegerOperation(op code.Opcode, left object.Object, right object.Object) error {
+	switch op {
+	case code.OpAdd, code.OpSub, code.OpMul, code.OpDiv, code.OpMod:
+		res := object.IntegerArithmetic(operators[op], left, right)
+		if errObj, ok := res.(*object.Error); ok {
+			return errObj
+		}
+		return vm.push(res)
+	case code.OpBitAnd, code.OpBitOr, code.OpBitXor, code.OpShiftLeft, code.OpShiftRight:
+		res := object.IntegerBitwise(operators[op], left, right)
+		if errObj, ok := res.(*object.Error); ok {
+			return errObj
+		}
+		return vm.push(res)
+	case code.OpRange, code.OpRangeExclusive:
+		res := object.NewRange(operators[op], left, right)
+		if errObj, ok := res.(*object.Error); ok {
+			return errObj
+		}
+		return vm.push(res)
+	case code.OpEqual:
+		return vm.push(nativeBoolToBooleanObject(object.CompareIntegers(left, right) == 0))
+	case code.OpNotEqual:
+		return vm.push(nativeBoolToBooleanObject(object.CompareIntegers(left, right) != 0))
+	case code.OpLessThan:
+		return vm.push(nativeBoolToBooleanObject(object.CompareIntegers(left, right) < 0))
+	case code.OpGreaterThan:
+		return vm.push(nativeBoolToBooleanObject(object.CompareIntegers(left, right) > 0))
+	case code.OpLessEqual:
+		return vm.push(nativeBoolToBooleanObject(object.CompareIntegers(left, right) <= 0))
+	case code.OpGreaterEqual:
+		return vm.push(nativeBoolToBooleanObject(object.CompareIntegers(left, right) >= 0))
+	default:
+		return newError("unknown operator %s %s %s", left.Type(), operators[op], right.Type())
+	}
+}
+
+// 片方が整数の場合は浮動小数点数に変換して計算する
+func (vm *VM) executeFloatOperation(op code.Opcode, left object.Object, right object.Object) error {
+	lv, _ := object.ToFloat(left)
+	rv, _ := object.ToFloat(right)
+
+	switch op {
+	case code.OpAdd, code.OpSub, code.OpMul, code.OpDiv, code.OpMod:
+		res := object.FloatArithmetic(operators[op], lv, rv)
+		if errObj, ok := res.(*object.Error); ok {
+			return errObj
+		}
+		return vm.push(res)
+	case code.OpEqual:
+		return vm.push(nativeBoolToBooleanObject(lv == rv))
+	case code.OpNotEqual:
+		return vm.push(nativeBoolToBooleanObject(lv != rv))
+	case code.OpLessThan:
+		return vm.push(nativeBoolToBooleanObject(lv < rv))
+	case code.OpGreaterThan:
+		return vm.push(nativeBoolToBooleanObject(lv > rv))
+	case code.OpLessEqual:
+		return vm.push(nativeBoolToBooleanObject(lv <= rv))
+	case code.OpGreaterEqual:
+		return vm.push(nativeBoolToBooleanObject(lv >= rv))
+	default:
+		return newError("unknown operator %s %s %s", left.Type(), operators[op], right.Type())
+	}
+}
+
+func (vm *VM) executeStringOperation(op code.Opcode, left object.Object, right object.Object) error {
+	lv := left.(*object.String).Value
+	rv := right.(*object.String).Value
+
+	switch op {
+	case code.OpAdd:
+		return vm.push(&object.String{Value: lv + rv})
+	case code.OpEqual:
+		return vm.push(nativeBoolToBooleanObject(lv == rv))
+	case code.OpNotEqual:
+		return vm.push(nativeBoolToBooleanObject(lv != rv))
+	default:
+		return newError("unknown operator %s %s %s", left.Type(), operators[op], right.Type())
+	}
+}
+
+// NULLとFALSEのみを偽とし、それ以外はすべて真とする
+func isTruthy(obj object.Object) bool {
+	switch obj {
+	case NULL, FALSE:
+		return false
+	default:
+		return true
+	}
+}
+
+func nativeBoolToBooleanObject(v bool) *object.Boolean {
+	if v {
+		return TRUE
+	}
+	return FALSE
+}
+
+func (vm *VM) buildHash(start int, end int) (object.Object, error) {
+	hash := object.NewHash()
+
+	for i := start; i < end; i += 2 {
+		key := vm.stack[i]
+		value := vm.stack[i+1]
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return nil, newError("unusable as hash key: %s", key.Type())
+		}
+
+		hash.Set(hashKey, value)
+	}
+
+	return hash, nil
+}
+
+func (vm *VM) executeIndexExpression(left object.Object, index object.Object) error {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		elements := left.(*object.Array).Elements
+
+		idx, ok := index.(*object.Integer)
+		if !ok || idx.Value < 0 || idx.Value >= int64(len(elements)) {
+			return newError("index out of range: %s (length %d)", index.Inspect(), len(elements))
+		}
+
+		return vm.push(elements[idx.Value])
+
+	case left.Type() == object.HASH_OBJ:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", index.Type())
+		}
+
+		value, ok := left.(*object.Hash).Get(key)
+		if !ok {
+			return vm.push(NULL)
+		}
+
+		return vm.push(value)
+
+	default:
+		return newError("index operator not supported: %s[%s]", left.Type(), index.Type())
+	}
+}
+
+func (vm *VM) executeCall(numArgs int) error {
+	if err := vm.checkAborted(); err != nil {
+		return err
+	}
+
+	callee := vm.stack[vm.sp-1-numArgs]
+
+	args, spread, errObj := object.SpreadArguments(vm.stack[vm.sp-numArgs : vm.sp])
+	if errObj != nil {
+		return errObj
+	}
+	if spread {
+		if err := vm.replaceArguments(numArgs, args); err != nil {
+			return err
+		}
+		numArgs = len(args)
+	}
+
+	switch callee := callee.(type) {
+	case *object.Closure:
+		return vm.callClosure(callee, numArgs)
+	case *object.Builtin:
+		return vm.callBuiltin(callee, numArgs)
+	default:
+		return newError("not a function: %s", callee.Type())
+	}
+}
+
+// スタックの先頭にある引数をargsに置き換える
+func (vm *VM) replaceArguments(numArgs int, args []object.Object) error {
+	vm.sp -= numArgs
+	if err := vm.reserve(len(args)); err != nil {
+		return err
+	}
+	copy(vm.stack[vm.sp:], args)
+	vm.sp += len(args)
+
+	return nil
+}
+
+func (vm *VM) callClosure(cl *object.Closure, numArgs int) error {
+	if numArgs != cl.Fn.NumParameters || !isPositional(cl.Fn.Literal, vm.stack[vm.sp-numArgs:vm.sp]) {
+		args, errObj := object.BindArguments(cl.Fn.Literal, vm.stack[vm.sp-numArgs:vm.sp])
+		if errObj != nil {
+			return errObj
+		}
+		if err := vm.replaceArguments(numArgs, args); err != nil {
+			return err
+		}
+		numArgs = len(args)
+	}
+
+	frame := NewFrame(cl, vm.sp-numArgs)
+	if err := vm.pushFrame(frame); err != nil {
+		return err
+	}
+
+	// 引数を除くローカル変数の領域を確保する（未定義はnilとする）
+	top := frame.basePointer + cl.Fn.NumLocals
+	if err := vm.reserve(top - vm.sp); err != nil {
+		return err
+	}
+	for i := vm.sp; i < top; i++ {
+		vm.stack[i] = nil
+	}
+	vm.sp = top
+
+	return nil
+}
+
+// 引数をそのままローカル変数とすることができるかどうか
+func isPositional(fn *ast.FunctionLiteral, args []object.Object) bool {
+	if fn.Rest != nil {
+		return false
+	}
+	for _, arg := range args {
+		if _, ok := arg.(*object.Keyword); ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (vm *VM) callBuiltin(builtin *object.Builtin, numArgs int) error {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(*builtinPanic); !ok {
+				r = &builtinPanic{value: r, name: builtin.Name}
+			}
+			panic(r)
+		}
+	}()
+
+	args := vm.stack[vm.sp-numArgs : vm.sp]
+
+	result := builtin.Call(vm.call, args...)
+	vm.sp = vm.sp - numArgs - 1
+
+	if errObj, ok := result.(*object.Error); ok {
+		return errObj
+	}
+	if result == nil {
+		result = NULL
+	}
+
+	return vm.push(result)
+}
+
+func (vm *VM) pushClosure(constIndex int, numFree int) error {
+	fn, ok := vm.constants[constIndex].(*object.CompiledFunction)
+	if !ok {
+		return fmt.Errorf("not a function: %+v", vm.constants[constIndex])
+	}
+
+	free := make([]object.Object, numFree)
+	copy(free, vm.stack[vm.sp-numFree:vm.sp])
+	vm.sp -= numFree
+
+	return vm.push(&object.Closure{Fn: fn, Free: free})
+}
@@ -0,0 +1,127 @@
+package vm
+
+import (
+	"context"
+	"minimonkey/compiler"
+	"minimonkey/lexer"
+	"minimonkey/object"
+	"minimonkey/parser"
+	"testing"
+)
+
+// 実行時のエラーは評価器と同様に*object.Errorとして返す
+func testEval(input string) object.Object {
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.Parse()
+
+	c := compiler.New()
+	if err := c.Compile(program); err != nil {
+		return &object.Error{Message: err.Error()}
+	}
+
+	vm := New(c.Bytecode())
+	if err := vm.Run(); err != nil {
+		if errObj, ok := err.(*object.Error); ok {
+			return errObj
+		}
+		return &object.Error{Message: err.Error()}
+	}
+
+	return vm.Result()
+}
+
+func TestEvalFunctionLiteral(t *testing.T) {
+	tests := []struct {
+		input  string
+		params []string
+		body   string
+	}{
+		{"fn() { }", []string{}, "{}"},
+		{"fn() { return }", []string{}, "{ return; }"},
+		{"fn(x) { x + 2 }", []string{"x"}, "{ (x + 2); }"},
+		{"fn(x, y) { return x + y + 2; }", []string{"x", "y"}, "{ return ((x + y) + 2); }"},
+		{"fn(x) { x + 1; x + 2 }", []string{"x"}, "{ (x + 1); (x + 2); }"},
+	}
+
+	for _, tt := range tests {
+		evaluted := testEval(tt.input)
+		cl, ok := evaluted.(*object.Closure)
+		if !ok {
+			t.Errorf("evaluted is %T, expected %s", evaluted, "Closure")
+			return
+		}
+		fn := cl.Fn.Literal
+
+		if cl.Fn.NumParameters != len(tt.params) {
+			t.Errorf("fn.Parameters has wrong parametes. expect %d parameters", len(tt.params))
+			return
+		}
+
+		for i, p := range fn.Parameters {
+			if p.String() != tt.params[i] {
+				t.Errorf("p.String() got %q, expect %q", p.String(), tt.params[i])
+				return
+			}
+		}
+
+		if fn.Body.String() != tt.body {
+			t.Errorf("fn.Body.String() got %q, expect %q", fn.Body.String(), tt.body)
+			return
+		}
+	}
+}
+
+func TestRunContext(t *testing.T) {
+	program := parser.New(lexer.New("let loop = fn() { loop() }; loop()")).Parse()
+
+	c := compiler.New()
+	if err := c.Compile(program); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := New(c.Bytecode()).RunContext(ctx)
+	if err == nil || err.Error() != "1:29: evaluation aborted: context canceled" {
+		t.Errorf("RunContext() got error %v, expected %q", err, "1:29: evaluation aborted: context canceled")
+	}
+}
+
+// 評価器は末尾呼び出しを最適化するため無限に再帰するが、VMはフレーム数を制限する
+func TestStackOverflow(t *testing.T) {
+	evaluted := testEval("let f = fn() { f() }; f()")
+	if evaluted.Inspect() != "ERROR: 1:16: stack overflow" {
+		t.Errorf("evaluted.Inspect() got %q, expected %q", evaluted.Inspect(), "ERROR: 1:16: stack overflow")
+	}
+}
+
+func TestRunRecoverPanic(t *testing.T) {
+	input := `let f = fn() { boom() + 1 };
+let g = fn(x) {
+  f() + x
+};
+g(1)`
+	expected := "ERROR: 1:16: internal error: boom\n    at boom (1:16)\n    at f (3:3)\n    at g (5:1)"
+
+	c := compiler.New()
+	c.SymbolTable().Define("boom")
+	if err := c.Compile(parser.New(lexer.New(input)).Parse()); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	globals := make([]object.Object, GLOBALS_SIZE)
+	globals[0] = &object.Builtin{Name: "boom", Fn: func(args ...object.Object) object.Object {
+		panic("boom")
+	}}
+
+	err := NewWithGlobals(c.Bytecode(), globals).Run()
+	errObj, ok := err.(*object.Error)
+	if !ok {
+		t.Fatalf("err is %T (%v), expected *object.Error", err, err)
+	}
+	if errObj.Inspect() != expected {
+		t.Errorf("errObj.Inspect() got %q, expected %q", errObj.Inspect(), expected)
+	}
+}
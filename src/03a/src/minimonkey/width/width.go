@@ -0,0 +1,34 @@
+// 端末上での文字の表示幅
+package width
+
+import "unicode"
+
+// 文字の表示幅（全角文字は2桁、結合文字は0桁）
+func Rune(r rune) int {
+	switch {
+	case r == 0 || unicode.Is(unicode.Mn, r):
+		return 0
+	case 0x1100 <= r && r <= 0x115F,
+		0x2E80 <= r && r <= 0xA4CF && r != 0x303F,
+		0xAC00 <= r && r <= 0xD7A3,
+		0xF900 <= r && r <= 0xFAFF,
+		0xFE30 <= r && r <= 0xFE4F,
+		0xFF00 <= r && r <= 0xFF60,
+		0xFFE0 <= r && r <= 0xFFE6,
+		0x1F300 <= r && r <= 0x1F64F,
+		0x1F900 <= r && r <= 0x1F9FF,
+		0x20000 <= r && r <= 0x3FFFD:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// 文字列の表示幅
+func String(s string) int {
+	w := 0
+	for _, r := range s {
+		w += Rune(r)
+	}
+	return w
+}
@@ -0,0 +1,77 @@
+package width
+
+import "testing"
+
+func TestRune(t *testing.T) {
+	tests := []struct {
+		input    rune
+		expected int
+	}{
+		{'a', 1},
+		{' ', 1},
+		{0, 0},
+		{'\u0301', 0}, // 結合用アキュート・アクセント
+		{'\u3099', 0}, // 結合用濁点
+		{'\u10FF', 1},
+		{'\u1100', 2}, // ハングル字母
+		{'\u115F', 2},
+		{'\u1160', 1},
+		{'\u2E7F', 1},
+		{'\u2E80', 2}, // CJK部首補助
+		{'あ', 2},
+		{'漢', 2},
+		{'\u303F', 1},
+		{'\uA4CF', 2},
+		{'\uA4D0', 1},
+		{'\uAC00', 2}, // ハングル音節
+		{'\uD7A3', 2},
+		{'\uD7A4', 1},
+		{'\uF900', 2}, // CJK互換漢字
+		{'\uFAFF', 2},
+		{'\uFE30', 2}, // CJK互換形
+		{'\uFE4F', 2},
+		{'\uFE50', 1},
+		{'\uFF01', 2}, // 全角英数記号
+		{'\uFF60', 2},
+		{'\uFF61', 1}, // 半角カナ
+		{'\uFFE0', 2},
+		{'\uFFE6', 2},
+		{'\uFFE7', 1},
+		{'\U0001F300', 2}, // 絵文字
+		{'\U0001F64F', 2},
+		{'\U0001F650', 1},
+		{'\U0001F900', 2},
+		{'\U0001F9FF', 2},
+		{'\U00020000', 2}, // CJK統合漢字拡張B
+		{'\U0003FFFD', 2},
+		{'\U0003FFFE', 1},
+	}
+
+	for _, tt := range tests {
+		if got := Rune(tt.input); got != tt.expected {
+			t.Errorf("Rune(%U) got %d, expected %d", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"日本語", 6},
+		{"aあ", 3},
+		{"e\u0301", 1}, // 結合文字を含む
+		{"か\u3099", 2},
+		{"ｶﾀｶﾅ", 4},
+		{"👍ok", 4},
+	}
+
+	for _, tt := range tests {
+		if got := String(tt.input); got != tt.expected {
+			t.Errorf("String(%q) got %d, expected %d", tt.input, got, tt.expected)
+		}
+	}
+}